@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+)
+
+type benchOptions struct {
+	definitionOptions
+	requestOptions
+
+	Rate        int           `short:"r" long:"rate" description:"target number of requests per second (default: as many as possible)"`
+	Concurrency int           `short:"c" long:"concurrency" description:"number of concurrent requests" default:"10"`
+	Duration    time.Duration `long:"duration" description:"duration of the benchmark" default:"10s"`
+	Timeout     time.Duration `long:"timeout" description:"timeout of a request" default:"10s"`
+
+	Arguments struct {
+		Method methodName `positional-arg-name:"Service.Method" required:"1"`
+	} `positional-args:"true"`
+}
+
+// bench calls a method of the server concurrently at the target rate and
+// reports latencies, throughput and errors.
+func bench(args []string) {
+	var opts benchOptions
+	parser := flags.NewParser(&opts, flags.Default)
+	parser.Name = "gorpc bench"
+	if _, err := parser.ParseArgs(args); err != nil {
+		return
+	}
+
+	if err := runBench(opts, os.Stdin, os.Stdout); err != nil {
+		printErr(err)
+		os.Exit(1)
+	}
+}
+
+// maxBenchRate is the highest rate whose interval between requests is
+// at least a nanosecond.
+const maxBenchRate = int(time.Second)
+
+func runBench(opts benchOptions, stdin io.Reader, stdout io.Writer) error {
+	switch {
+	case opts.Rate < 0 || opts.Rate > maxBenchRate:
+		return fmt.Errorf("--rate must be between 0 and %d", maxBenchRate)
+	case opts.Concurrency < 1:
+		return fmt.Errorf("--concurrency must be positive")
+	case opts.Duration <= 0:
+		return fmt.Errorf("--duration must be positive")
+	}
+
+	body, err := benchBody(opts, stdin)
+	if err != nil {
+		return err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = opts.Concurrency
+	client := &http.Client{Transport: transport, Timeout: opts.Timeout}
+
+	// check the request before flooding the server with it
+	if _, err := newCallRequest(opts.Host, string(opts.Arguments.Method), opts.Headers, body); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Duration)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		results benchResults
+		started = time.Now()
+		tickets = benchTickets(ctx, opts.Rate)
+	)
+
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range tickets {
+				r, _ := newCallRequest(opts.Host, string(opts.Arguments.Method), opts.Headers, body)
+				callStarted := time.Now()
+				outcome := benchCall(client, r)
+				results.add(time.Since(callStarted), outcome)
+			}
+		}()
+	}
+
+	wg.Wait()
+	results.elapsed = time.Since(started)
+
+	return results.report(stdout)
+}
+
+// benchBody returns the request body built from the options or
+// the example request of the method.
+func benchBody(opts benchOptions, stdin io.Reader) ([]byte, error) {
+	rootDefinition, err := loadDefinition(opts.definitionOptions, opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	method, ok := findMethod(rootDefinition, string(opts.Arguments.Method))
+	if !ok {
+		return nil, fmt.Errorf("%s is not defined", opts.Arguments.Method)
+	}
+
+	if opts.Data == "" && len(opts.Fields) == 0 {
+		example, err := rootDefinition.TypeExample(method.InputObject)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(example)
+	}
+
+	body, err := requestBody(opts.requestOptions, stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rootDefinition.Validate(method.InputObject, body); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	return body, nil
+}
+
+// benchTickets emits a ticket for every request to make until the context
+// is done. Tickets are emitted at the rate or as fast as they are taken.
+func benchTickets(ctx context.Context, rate int) <-chan struct{} {
+	tickets := make(chan struct{})
+	go func() {
+		defer close(tickets)
+
+		var tick <-chan time.Time
+		if rate > 0 {
+			ticker := time.NewTicker(time.Second / time.Duration(rate))
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			if tick != nil {
+				select {
+				case <-tick:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case tickets <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return tickets
+}
+
+// benchCall makes the request and returns empty outcome for successful
+// calls, status and code of client errors or the transport error.
+func benchCall(client *http.Client, r *http.Request) string {
+	resp, err := client.Do(r)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return err.Error()
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "read response body: " + err.Error()
+	}
+
+	var response struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+	_ = json.Unmarshal(body, &response)
+	if resp.StatusCode == http.StatusOK && response.Error == "" {
+		return ""
+	}
+
+	outcome := strconv.Itoa(resp.StatusCode)
+	if response.Code != "" {
+		outcome += " " + response.Code
+	}
+
+	return outcome
+}
+
+type benchResults struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    map[string]int
+	requests  int
+	elapsed   time.Duration
+}
+
+func (b *benchResults) add(latency time.Duration, outcome string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.requests++
+	b.latencies = append(b.latencies, latency)
+	if outcome != "" {
+		if b.errors == nil {
+			b.errors = make(map[string]int)
+		}
+
+		b.errors[outcome]++
+	}
+}
+
+func (b *benchResults) report(w io.Writer) error {
+	var failed int
+	outcomes := make([]string, 0, len(b.errors))
+	for outcome, count := range b.errors {
+		outcomes = append(outcomes, outcome)
+		failed += count
+	}
+
+	sort.Strings(outcomes)
+	sort.Slice(b.latencies, func(i, j int) bool {
+		return b.latencies[i] < b.latencies[j]
+	})
+
+	_, err := fmt.Fprintf(w, "Requests:   %d in %s (%.1f/s)\nSuccessful: %d\nFailed:     %d\n",
+		b.requests, b.elapsed.Round(time.Millisecond), float64(b.requests)/b.elapsed.Seconds(), b.requests-failed, failed)
+	if err != nil {
+		return err
+	}
+
+	if len(b.latencies) > 0 {
+		_, err := fmt.Fprintf(w, "Latency:    p50 %s, p90 %s, p99 %s, max %s\n",
+			percentile(b.latencies, 0.5), percentile(b.latencies, 0.9), percentile(b.latencies, 0.99), b.latencies[len(b.latencies)-1])
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(outcomes) > 0 {
+		if _, err := fmt.Fprintln(w, "Errors:"); err != nil {
+			return err
+		}
+	}
+
+	for _, outcome := range outcomes {
+		if _, err := fmt.Fprintf(w, "  %s: %d\n", outcome, b.errors[outcome]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	return sorted[rank]
+}
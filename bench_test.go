@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	latencies := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	tests := []struct {
+		p        float64
+		expected time.Duration
+	}{
+		{p: 0, expected: 1},
+		{p: 0.5, expected: 5},
+		{p: 0.9, expected: 9},
+		{p: 0.99, expected: 10},
+		{p: 1, expected: 10},
+	}
+
+	for _, test := range tests {
+		if got := percentile(latencies, test.p); got != test.expected {
+			t.Errorf("expected p%v to be %d, got %d", test.p*100, test.expected, got)
+		}
+	}
+}
+
+func TestRunBench(t *testing.T) {
+	rootDefinition, err := parseDefinition([]string{"./testdata/services/pleasantries"}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := newMockServer(rootDefinition, mockOptions{ErrorRate: 1, ErrorStatus: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	var opts benchOptions
+	opts.Host = httpServer.URL
+	opts.Arguments.Method = "GreeterService.Greet"
+	opts.Rate = 100
+	opts.Concurrency = 2
+	opts.Duration = 100 * time.Millisecond
+	opts.Timeout = time.Second
+
+	var stdout bytes.Buffer
+	if err := runBench(opts, strings.NewReader(""), &stdout); err != nil {
+		t.Fatal(err)
+	}
+
+	report := stdout.String()
+	if !strings.Contains(report, "Successful: 0\n") {
+		t.Errorf("expected no successful requests, got %q", report)
+	}
+
+	if !strings.Contains(report, "Errors:\n  500: ") {
+		t.Errorf("expected 500 errors, got %q", report)
+	}
+}
+
+func TestRunBenchInvalid(t *testing.T) {
+	valid := benchOptions{Rate: 100, Concurrency: 2, Duration: time.Second}
+	valid.Definitions = []string{"./testdata/services/pleasantries"}
+	valid.Arguments.Method = "GreeterService.Greet"
+
+	tests := []struct {
+		modify   func(opts *benchOptions)
+		expected string
+	}{
+		{modify: func(opts *benchOptions) { opts.Rate = -1 }, expected: "--rate must be between 0 and 1000000000"},
+		{modify: func(opts *benchOptions) { opts.Rate = 2e9 }, expected: "--rate must be between 0 and 1000000000"},
+		{modify: func(opts *benchOptions) { opts.Concurrency = 0 }, expected: "--concurrency must be positive"},
+		{modify: func(opts *benchOptions) { opts.Duration = 0 }, expected: "--duration must be positive"},
+		{modify: func(opts *benchOptions) { opts.Data = `{"names":"Mat"}` }, expected: "invalid request: "},
+	}
+
+	for _, test := range tests {
+		opts := valid
+		test.modify(&opts)
+		err := runBench(opts, strings.NewReader(""), &bytes.Buffer{})
+		if err == nil || !strings.HasPrefix(err.Error(), test.expected) {
+			t.Errorf("expected %q error, got %v", test.expected, err)
+		}
+	}
+}
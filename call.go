@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/damejeras/gorpc/definition"
+	"github.com/jessevdk/go-flags"
+)
+
+// definitionOptions locate the server and its definition.
+type definitionOptions struct {
+	Host        string   `short:"H" long:"host" description:"URL of the server" default:"http://localhost:8080/"`
+	Definitions []string `short:"d" long:"definition" description:"service definition to parse, can be repeated (default: fetched from the reflection endpoint of the host)"`
+	Ignore      string   `short:"i" long:"ignore"  description:"comma separated list of interfaces to ignore"`
+	Parameters  string   `long:"parameters" description:"list of parameters in the format \"key:value,key:value\""`
+}
+
+// requestOptions build the request body and headers.
+type requestOptions struct {
+	Data    string   `long:"data" description:"JSON request body, @file reads it from the file and @- from stdin"`
+	Fields  []string `short:"f" long:"field" description:"request field as name=value, can be repeated; nested names are separated by dots, values are parsed as JSON when possible"`
+	Headers []string `long:"header" description:"request header as \"Name: value\", can be repeated"`
+}
+
+type callOptions struct {
+	definitionOptions
+	requestOptions
+
+	Template bool          `long:"template" description:"print the example request of the method"`
+	List     bool          `long:"list" description:"list the methods"`
+	Raw      bool          `long:"raw" description:"print the response body as received"`
+	Timeout  time.Duration `long:"timeout" description:"timeout of the call" default:"10s"`
+
+	Arguments struct {
+		Method methodName `positional-arg-name:"Service.Method"`
+	} `positional-args:"true"`
+}
+
+// call calls a method of the server described by the definition.
+func call(args []string) {
+	var opts callOptions
+	parser := flags.NewParser(&opts, flags.Default)
+	parser.Name = "gorpc call"
+	if _, err := parser.ParseArgs(args); err != nil {
+		return
+	}
+
+	if err := runCall(opts, os.Stdin, os.Stdout); err != nil {
+		printErr(err)
+		os.Exit(1)
+	}
+}
+
+func runCall(opts callOptions, stdin io.Reader, stdout io.Writer) error {
+	rootDefinition, err := loadDefinition(opts.definitionOptions, opts.Timeout)
+	if err != nil {
+		return err
+	}
+
+	if opts.List {
+		for _, name := range methodNames(rootDefinition) {
+			_, _ = fmt.Fprintln(stdout, name)
+		}
+
+		return nil
+	}
+
+	if opts.Arguments.Method == "" {
+		return fmt.Errorf("Service.Method is required")
+	}
+
+	method, ok := findMethod(rootDefinition, string(opts.Arguments.Method))
+	if !ok {
+		return fmt.Errorf("%s is not defined", opts.Arguments.Method)
+	}
+
+	if opts.Template {
+		example, err := rootDefinition.TypeExample(method.InputObject)
+		if err != nil {
+			return err
+		}
+
+		return printJSON(stdout, example)
+	}
+
+	body, err := requestBody(opts.requestOptions, stdin)
+	if err != nil {
+		return err
+	}
+
+	if err := rootDefinition.Validate(method.InputObject, body); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+
+	r, err := newCallRequest(opts.Host, string(opts.Arguments.Method), opts.Headers, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := (&http.Client{Timeout: opts.Timeout}).Do(r)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+
+	if opts.Raw {
+		_, _ = stdout.Write(respBody)
+	} else if err := printIndented(stdout, respBody); err != nil {
+		return err
+	}
+
+	var response struct {
+		Error string `json:"error"`
+	}
+	_ = json.Unmarshal(respBody, &response)
+	if resp.StatusCode != http.StatusOK || response.Error != "" {
+		return fmt.Errorf("%s: %s", opts.Arguments.Method, resp.Status)
+	}
+
+	return nil
+}
+
+// newCallRequest returns the request calling the method with the headers
+// in the format "Name: value".
+func newCallRequest(host, name string, headers []string, body []byte) (*http.Request, error) {
+	r, err := http.NewRequest(http.MethodPost, methodURL(host, name), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return nil, fmt.Errorf("header %q: expected \"Name: value\"", header)
+		}
+
+		r.Header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	return r, nil
+}
+
+// loadDefinition parses the definitions or fetches the definition from
+// the reflection endpoint of the host.
+func loadDefinition(opts definitionOptions, timeout time.Duration) (*definition.Root, error) {
+	if len(opts.Definitions) > 0 {
+		return parseDefinition(opts.Definitions, opts.Ignore, opts.Parameters)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(methodURL(opts.Host, "gorpc.Reflection.Definition"))
+	if err != nil {
+		return nil, fmt.Errorf("fetch definition: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch definition: %s (is reflection enabled on the server?)", resp.Status)
+	}
+
+	var rootDefinition definition.Root
+	if err := json.NewDecoder(resp.Body).Decode(&rootDefinition); err != nil {
+		return nil, fmt.Errorf("decode definition: %w", err)
+	}
+
+	return &rootDefinition, nil
+}
+
+// requestBody reads the body from --data and sets the --field values.
+func requestBody(opts requestOptions, stdin io.Reader) ([]byte, error) {
+	body := []byte(opts.Data)
+	switch {
+	case opts.Data == "":
+		body = []byte("{}")
+	case opts.Data == "@-":
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+
+		body = data
+	case strings.HasPrefix(opts.Data, "@"):
+		data, err := os.ReadFile(opts.Data[1:])
+		if err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+
+		body = data
+	}
+
+	if len(opts.Fields) == 0 {
+		return body, nil
+	}
+
+	var request map[string]interface{}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, fmt.Errorf("decode request body: %w", err)
+	}
+
+	if request == nil {
+		request = make(map[string]interface{})
+	}
+
+	for _, field := range opts.Fields {
+		name, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("field %q: expected name=value", field)
+		}
+
+		if err := setField(request, strings.Split(name, "."), fieldValue(value)); err != nil {
+			return nil, fmt.Errorf("field %q: %w", field, err)
+		}
+	}
+
+	return json.Marshal(request)
+}
+
+// setField sets the value of the nested field, creating objects on the way.
+func setField(object map[string]interface{}, path []string, value interface{}) error {
+	if len(path) == 1 {
+		object[path[0]] = value
+
+		return nil
+	}
+
+	nested, ok := object[path[0]].(map[string]interface{})
+	if !ok {
+		if object[path[0]] != nil {
+			return fmt.Errorf("%s is not an object", path[0])
+		}
+
+		nested = make(map[string]interface{})
+		object[path[0]] = nested
+	}
+
+	return setField(nested, path[1:], value)
+}
+
+// fieldValue parses the value as JSON and falls back to a string.
+func fieldValue(value string) interface{} {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		return value
+	}
+
+	return parsed
+}
+
+func findMethod(rootDefinition *definition.Root, name string) (definition.Method, bool) {
+	for _, service := range rootDefinition.Services {
+		for _, method := range service.Methods {
+			if service.Name+"."+method.Name == name {
+				return method, true
+			}
+		}
+	}
+
+	return definition.Method{}, false
+}
+
+func methodNames(rootDefinition *definition.Root) []string {
+	var names []string
+	for _, service := range rootDefinition.Services {
+		for _, method := range service.Methods {
+			names = append(names, service.Name+"."+method.Name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func methodURL(host, name string) string {
+	return strings.TrimSuffix(host, "/") + "/" + name
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+
+	return err
+}
+
+// printIndented pretty-prints JSON and prints anything else as is.
+func printIndented(w io.Writer, data []byte) error {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err != nil {
+		_, err = w.Write(data)
+
+		return err
+	}
+
+	_, err := fmt.Fprintln(w, indented.String())
+
+	return err
+}
+
+// methodName completes names of the methods defined by the --definition
+// or --host options found in the command line.
+type methodName string
+
+func (m *methodName) Complete(match string) []flags.Completion {
+	// option values are not set while completing, parse them again
+	_ = os.Unsetenv("GO_FLAGS_COMPLETION")
+
+	var opts definitionOptions
+	_, _ = flags.NewParser(&opts, flags.IgnoreUnknown).ParseArgs(os.Args[2:])
+
+	rootDefinition, err := loadDefinition(opts, time.Second)
+	if err != nil {
+		return nil
+	}
+
+	var completions []flags.Completion
+	for _, name := range methodNames(rootDefinition) {
+		if strings.HasPrefix(name, match) {
+			completions = append(completions, flags.Completion{Item: name})
+		}
+	}
+
+	return completions
+}
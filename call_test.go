@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRunCall(t *testing.T) {
+	rootDefinition, err := parseDefinition([]string{"./testdata/services/pleasantries"}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := newMockServer(rootDefinition, mockOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	tests := []struct {
+		opts     callOptions
+		expected string
+		err      string
+	}{
+		{
+			opts:     callOptions{List: true},
+			expected: "GreeterService.GetGreetings\nGreeterService.Greet\nIgnorer.Ignore\nWelcomer.Welcome\n",
+		},
+		{
+			opts:     callOptions{Template: true},
+			expected: "{\n  \"names\": [\n    \"Mat\",\n    \"David\"\n  ]\n}\n",
+		},
+		{
+			opts:     callOptions{requestOptions: requestOptions{Fields: []string{`names=["Mat"]`}}},
+			expected: "{\n  \"greeting\": {\n    \"text\": \"Hello there\"\n  }\n}\n",
+		},
+		{
+			opts: callOptions{requestOptions: requestOptions{Data: `{"names":"Mat"}`}},
+			err:  "invalid request: $.names: expected array, got string",
+		},
+	}
+
+	for _, test := range tests {
+		test.opts.Host = httpServer.URL
+		test.opts.Arguments.Method = "GreeterService.Greet"
+
+		var stdout bytes.Buffer
+		err := runCall(test.opts, strings.NewReader(""), &stdout)
+		if test.err == "" && err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+
+		if test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Errorf("expected %q error, got %v", test.err, err)
+		}
+
+		if stdout.String() != test.expected {
+			t.Errorf("expected %q output, got %q", test.expected, stdout.String())
+		}
+	}
+}
+
+func TestRequestBody(t *testing.T) {
+	opts := requestOptions{
+		Data:   "@-",
+		Fields: []string{"page.cursor=abc", "times=3", "name=Mat"},
+	}
+
+	body, err := requestBody(opts, strings.NewReader(`{"page":{"orderAsc":true}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"name":"Mat","page":{"cursor":"abc","orderAsc":true},"times":3}`
+	if string(body) != expected {
+		t.Errorf("expected %q request body, got %q", expected, string(body))
+	}
+}
@@ -1,6 +1,9 @@
 package definition
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Example generates an object that is a realistic example
 // of this object.
@@ -10,11 +13,7 @@ func (d *Root) Example(o Object) (map[string]interface{}, error) {
 	obj := make(map[string]interface{})
 	for _, field := range o.Fields {
 		if field.Type.IsObject {
-			subobj, err := d.Object(field.Type.CleanObjectName)
-			if err != nil {
-				return nil, fmt.Errorf("Object(%q): %w", field.Type.CleanObjectName, err)
-			}
-			example, err := d.ExampleP(subobj)
+			example, err := d.TypeExample(field.Type)
 			if err != nil {
 				return nil, err
 			}
@@ -26,6 +25,10 @@ func (d *Root) Example(o Object) (map[string]interface{}, error) {
 			continue
 		}
 		obj[field.NameLowerCamel] = field.Example
+		if _, isList := field.Example.([]interface{}); isList {
+			// example is already a list of values
+			continue
+		}
 		if field.Type.Multiple {
 			// turn it into an array
 			obj[field.NameLowerCamel] = []interface{}{obj[field.NameLowerCamel], obj[field.NameLowerCamel], obj[field.NameLowerCamel]}
@@ -38,3 +41,26 @@ func (d *Root) Example(o Object) (map[string]interface{}, error) {
 func (d *Root) ExampleP(o *Object) (map[string]interface{}, error) {
 	return d.Example(*o)
 }
+
+// TypeExample generates an example of the object of this type.
+// Objects of imported types are looked up without package name.
+func (d *Root) TypeExample(typ FieldType) (map[string]interface{}, error) {
+	obj, err := d.typeObject(typ)
+	if err != nil {
+		return nil, err
+	}
+	return d.ExampleP(obj)
+}
+
+// typeObject looks up the object of this type without package name.
+func (d *Root) typeObject(typ FieldType) (*Object, error) {
+	name := typ.CleanObjectName
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	obj, err := d.Object(name)
+	if err != nil {
+		return nil, fmt.Errorf("Object(%q): %w", typ.CleanObjectName, err)
+	}
+	return obj, nil
+}
@@ -46,6 +46,11 @@ func TestObjectExample(t *testing.T) {
 				NameLowerCamel: "languages",
 				Example:        "Go",
 			},
+			{
+				Type:           FieldType{TypeName: "string", Multiple: true, CleanObjectName: "string"},
+				NameLowerCamel: "editors",
+				Example:        []interface{}{"Vim", "GoLand"},
+			},
 		},
 	}
 	def := &Root{
@@ -64,9 +69,33 @@ func TestObjectExample(t *testing.T) {
 	languages, ok := favourites["languages"].([]interface{})
 	is.True(ok) // Languages []interface{}
 	is.Equal(len(languages), 3)
+	editors, ok := favourites["editors"].([]interface{})
+	is.True(ok) // Editors []interface{}
+	is.Equal(len(editors), 2)
 
 	exampleJSON, err := def.Example(obj1)
 	is.NoErr(err)
 	is.Equal(len(exampleJSON), 4)
 
 }
+
+func TestTypeExample(t *testing.T) {
+	is := is.New(t)
+
+	def := &Root{
+		Objects: []Object{
+			{
+				Name: "Page",
+				Fields: []Field{
+					{NameLowerCamel: "cursor", Example: "abc"},
+				},
+			},
+		},
+	}
+	example, err := def.TypeExample(FieldType{CleanObjectName: "services.Page", IsObject: true})
+	is.NoErr(err)
+	is.Equal(example["cursor"], "abc")
+
+	_, err = def.TypeExample(FieldType{CleanObjectName: "Missing", IsObject: true})
+	is.True(err != nil)
+}
@@ -0,0 +1,90 @@
+package definition
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Validate checks that data is a JSON object of this type: it has no
+// fields the object does not have and the values have JSON types of
+// the fields. Missing fields and null values are allowed.
+func (d *Root) Validate(typ FieldType, data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return d.validateObject("$", typ, value)
+}
+
+func (d *Root) validateObject(path string, typ FieldType, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: expected object, got %s", path, jsonType(value))
+	}
+	obj, err := d.typeObject(typ)
+	if err != nil {
+		return err
+	}
+	fields := make(map[string]Field, len(obj.Fields))
+	for _, field := range obj.Fields {
+		fields[field.NameLowerCamel] = field
+	}
+	for key, value := range values {
+		field, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("%s: unknown field", path+"."+key)
+		}
+		if err := d.validateField(path+"."+key, field.Type, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Root) validateField(path string, typ FieldType, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	if typ.Multiple {
+		values, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %s", path, jsonType(value))
+		}
+		typ.Multiple = false
+		for i := range values {
+			if err := d.validateField(fmt.Sprintf("%s[%d]", path, i), typ, values[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if typ.IsObject {
+		return d.validateObject(path, typ, value)
+	}
+	switch typ.JSType {
+	case "string", "number", "boolean", "object":
+		if jsonType(value) != typ.JSType {
+			return fmt.Errorf("%s: expected %s, got %s", path, typ.JSType, jsonType(value))
+		}
+	}
+	return nil
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
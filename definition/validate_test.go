@@ -0,0 +1,46 @@
+package definition
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestValidate(t *testing.T) {
+	is := is.New(t)
+
+	def := Root{
+		Objects: []Object{
+			{
+				Name: "GreetRequest",
+				Fields: []Field{
+					{NameLowerCamel: "names", Type: FieldType{JSType: "string", Multiple: true}},
+					{NameLowerCamel: "times", Type: FieldType{JSType: "number"}},
+					{NameLowerCamel: "page", Type: FieldType{CleanObjectName: "services.Page", IsObject: true}},
+				},
+			},
+			{
+				Name: "Page",
+				Fields: []Field{
+					{NameLowerCamel: "cursor", Type: FieldType{JSType: "string"}},
+				},
+			},
+		},
+	}
+	typ := FieldType{CleanObjectName: "GreetRequest", IsObject: true}
+
+	is.NoErr(def.Validate(typ, []byte(`{"names":["Mat"],"times":2,"page":{"cursor":"abc"}}`)))
+	is.NoErr(def.Validate(typ, []byte(`{"names":null,"page":null}`)))
+	is.NoErr(def.Validate(typ, []byte(`{}`)))
+
+	err := def.Validate(typ, []byte(`{"names":"Mat"}`))
+	is.Equal(err.Error(), "$.names: expected array, got string")
+	err = def.Validate(typ, []byte(`{"names":["Mat",1]}`))
+	is.Equal(err.Error(), "$.names[1]: expected string, got number")
+	err = def.Validate(typ, []byte(`{"page":{"cursor":true}}`))
+	is.Equal(err.Error(), "$.page.cursor: expected string, got boolean")
+	err = def.Validate(typ, []byte(`{"name":"Mat"}`))
+	is.Equal(err.Error(), "$.name: unknown field")
+	err = def.Validate(typ, []byte(`[]`))
+	is.Equal(err.Error(), "$: expected object, got array")
+}
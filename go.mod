@@ -1,11 +1,18 @@
 module github.com/damejeras/gorpc
 
-go 1.16
+go 1.21
 
 require (
+	github.com/damejeras/gorpc/transport v0.1.0
 	github.com/fatih/structtag v1.2.0
 	github.com/jessevdk/go-flags v1.5.0
 	github.com/matryer/is v1.4.0
 	github.com/pkg/errors v0.9.1
 	golang.org/x/tools v0.1.5
 )
+
+require (
+	golang.org/x/mod v0.4.2 // indirect
+	golang.org/x/sys v0.0.0-20210510120138-977fb7262007 // indirect
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
+)
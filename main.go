@@ -26,24 +26,27 @@ var options struct {
 	} `positional-args:"true"`
 }
 
+// commands are run by "gorpc <command>", templates are rendered otherwise.
+var commands = map[string]func(args []string){
+	"bench": bench,
+	"call":  call,
+	"mock":  mock,
+}
+
 func main() {
-	if _, err := flags.Parse(&options); err != nil {
-		return
-	}
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			command(os.Args[2:])
 
-	definitionParser := definition.NewParser(options.Arguments.Input...)
-	exclusions := strings.Split(options.Ignore, ",")
-	if exclusions[0] != "" {
-		definitionParser.Exclusions = exclusions
+			return
+		}
 	}
 
-	parameters, err := definition.ParseParams(options.Parameters)
-	if err != nil {
-		printErr(err)
-		os.Exit(1)
+	if _, err := flags.Parse(&options); err != nil {
+		return
 	}
 
-	rootDefinition, err := definitionParser.ParseWithParams(parameters)
+	rootDefinition, err := parseDefinition(options.Arguments.Input, options.Ignore, options.Parameters)
 	if err != nil {
 		printErr(err)
 		os.Exit(1)
@@ -75,6 +78,23 @@ func main() {
 	}
 }
 
+// parseDefinition parses service definitions ignoring comma separated
+// interfaces, with parameters in the format "key:value,key:value".
+func parseDefinition(input []string, ignore, params string) (*definition.Root, error) {
+	definitionParser := definition.NewParser(input...)
+	exclusions := strings.Split(ignore, ",")
+	if exclusions[0] != "" {
+		definitionParser.Exclusions = exclusions
+	}
+
+	parameters, err := definition.ParseParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return definitionParser.ParseWithParams(parameters)
+}
+
 func printOutput(output *bytes.Buffer) error {
 	if options.Output != "" {
 		stat, err := os.Stat(options.Output)
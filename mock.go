@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/damejeras/gorpc/definition"
+	"github.com/damejeras/gorpc/transport"
+	"github.com/jessevdk/go-flags"
+)
+
+type mockOptions struct {
+	Address      string        `short:"a" long:"address" description:"address to listen on" default:"localhost:8080"`
+	PathPrefix   string        `long:"path-prefix" description:"prefix of the method paths"`
+	AllowOrigins []string      `long:"allow-origin" description:"origin allowed to call the server, can be repeated (\"*\" allows any)"`
+	Latency      time.Duration `long:"latency" description:"delay of every response, e.g. 200ms"`
+	ErrorRate    float64       `long:"error-rate" description:"share of calls answered with an injected error, from 0 to 1"`
+	ErrorStatus  int           `long:"error-status" description:"status code of injected errors" default:"500"`
+	Ignore       string        `short:"i" long:"ignore"  description:"comma separated list of interfaces to ignore"`
+	Parameters   string        `long:"parameters" description:"list of parameters in the format \"key:value,key:value\""`
+
+	Arguments struct {
+		Input []string `positional-arg-name:"service definition" required:"1"`
+	} `positional-args:"true"`
+}
+
+// mock serves every method of the definition with its example response.
+func mock(args []string) {
+	var opts mockOptions
+	parser := flags.NewParser(&opts, flags.Default)
+	parser.Name = "gorpc mock"
+	if _, err := parser.ParseArgs(args); err != nil {
+		return
+	}
+
+	rootDefinition, err := parseDefinition(opts.Arguments.Input, opts.Ignore, opts.Parameters)
+	if err != nil {
+		printErr(err)
+		os.Exit(1)
+	}
+
+	server, err := newMockServer(rootDefinition, opts)
+	if err != nil {
+		printErr(err)
+		os.Exit(1)
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "serving mock of %s on http://%s\n", rootDefinition.PackageName, opts.Address)
+	if err := http.ListenAndServe(opts.Address, server); err != nil {
+		printErr(err)
+		os.Exit(1)
+	}
+}
+
+// newMockServer registers every method of the definition. Methods validate
+// requests against their input objects, wait for the latency, fail with
+// the error rate and respond with examples of their output objects.
+func newMockServer(rootDefinition *definition.Root, opts mockOptions) (transport.Server, error) {
+	reflection, err := json.Marshal(rootDefinition)
+	if err != nil {
+		return nil, fmt.Errorf("encode definition: %w", err)
+	}
+
+	serverOptions := []transport.Option{
+		transport.WithReflection(reflection),
+		transport.WithAccessLog(slog.New(slog.NewTextHandler(os.Stderr, nil))),
+	}
+
+	if opts.PathPrefix != "" {
+		serverOptions = append(serverOptions, transport.WithPathPrefix(opts.PathPrefix))
+	}
+
+	if len(opts.AllowOrigins) > 0 {
+		serverOptions = append(serverOptions, transport.WithCORS(transport.CORSOptions{
+			AllowedOrigins: opts.AllowOrigins,
+			AllowedHeaders: []string{"*"},
+		}))
+	}
+
+	server := transport.NewServer(serverOptions...)
+	for _, service := range rootDefinition.Services {
+		for _, method := range service.Methods {
+			example, err := rootDefinition.TypeExample(method.OutputObject)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", service.Name, method.Name, err)
+			}
+
+			// example of the built-in Error field would make every response fail
+			delete(example, "error")
+
+			server.Register(service.Name, method.Name, mockHandler(server, rootDefinition, method, example, opts),
+				transport.WithMetadata(service.Metadata), transport.WithMetadata(method.Metadata))
+		}
+	}
+
+	return server, nil
+}
+
+func mockHandler(server transport.Server, rootDefinition *definition.Root, method definition.Method, example map[string]interface{}, opts mockOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request json.RawMessage
+		if err := transport.Decode(r, &request); err != nil {
+			server.OnErr(w, r, err)
+			return
+		}
+
+		if err := rootDefinition.Validate(method.InputObject, request); err != nil {
+			server.OnErr(w, r, transport.ClientError{Code: http.StatusBadRequest, Message: err.Error()})
+			return
+		}
+
+		if opts.Latency > 0 {
+			select {
+			case <-time.After(opts.Latency):
+			case <-r.Context().Done():
+				server.OnErr(w, r, r.Context().Err())
+				return
+			}
+		}
+
+		if opts.ErrorRate > 0 && rand.Float64() < opts.ErrorRate {
+			server.OnErr(w, r, transport.ClientError{Code: opts.ErrorStatus, Message: "injected error"})
+			return
+		}
+
+		if err := transport.Encode(w, r, http.StatusOK, example); err != nil {
+			server.OnErr(w, r, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMockServer(t *testing.T) {
+	rootDefinition, err := parseDefinition([]string{"./testdata/services/pleasantries"}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		opts     mockOptions
+		body     string
+		status   int
+		expected string
+	}{
+		{
+			body:     `{"name":"Mat","times":2}`,
+			status:   http.StatusOK,
+			expected: `{"message":"Welcome John Smith."}`,
+		},
+		{
+			body:     `{"name":2}`,
+			status:   http.StatusBadRequest,
+			expected: `{"error":"$.name: expected string, got number"}`,
+		},
+		{
+			opts:     mockOptions{ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable},
+			body:     `{}`,
+			status:   http.StatusServiceUnavailable,
+			expected: `{"error":"injected error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		server, err := newMockServer(rootDefinition, test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Welcomer.Welcome", strings.NewReader(test.body))
+		r.Header.Set("Content-Type", "application/json")
+		server.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s: expected %d status code, got %d", test.body, test.status, w.Code)
+		}
+
+		if w.Body.String() != test.expected {
+			t.Errorf("%s: expected %q response body, got %q", test.body, test.expected, w.Body.String())
+		}
+	}
+}
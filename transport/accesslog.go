@@ -0,0 +1,102 @@
+package transport
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// logAccess serves the request and writes an access log record with
+// the route, response status, duration and sizes of request and response
+// bodies. Server errors are logged with error level.
+func (s *server) logAccess(w http.ResponseWriter, r *http.Request) {
+	started := time.Now()
+
+	body := &countingReader{ReadCloser: r.Body}
+	if r.Body != nil {
+		r.Body = body
+	}
+
+	recorder := &statusRecorder{ResponseWriter: w}
+	rt := s.serve(recorder, r)
+
+	attrs := make([]slog.Attr, 0, 10)
+	if rt != nil {
+		attrs = append(attrs, slog.String("service", rt.service), slog.String("method", rt.method))
+	}
+
+	attrs = append(attrs,
+		slog.String("http_method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Int("status", recorder.Status()),
+		slog.Duration("duration", time.Since(started)),
+		slog.Int64("request_size", body.n),
+		slog.Int64("response_size", recorder.n),
+		slog.String("remote_addr", r.RemoteAddr),
+	)
+
+	if id := RequestIDFromContext(r.Context()); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+
+	level := slog.LevelInfo
+	if recorder.Status() >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+
+	s.accessLog.LogAttrs(r.Context(), level, "rpc", attrs...)
+}
+
+// countingReader counts bytes read from the request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// statusRecorder records status code and number of bytes written
+// to the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	n      int64
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+
+	n, err := s.ResponseWriter.Write(p)
+	s.n += int64(n)
+
+	return n, err
+}
+
+func (s *statusRecorder) Status() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+
+	return s.status
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
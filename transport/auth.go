@@ -0,0 +1,245 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+)
+
+// Principal is the authenticated caller of a method.
+type Principal struct {
+	// ID identifies the caller: API key owner, JWT subject, etc.
+	ID string
+	// Scheme is the name of the Authenticator that authenticated the caller.
+	Scheme string
+	// Claims holds additional attributes of the caller, e.g. JWT claims.
+	Claims map[string]interface{}
+}
+
+// Authenticator authenticates requests. Authenticate returns nil Principal
+// and nil error if the request carries no credentials of its scheme and
+// an error if the credentials are present but invalid.
+type Authenticator interface {
+	Scheme() string
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// credentialHeaders is implemented by authenticators to list request headers
+// they read credentials from. Authenticators that do not implement it are
+// assumed to use Authorization header.
+type credentialHeaders interface {
+	credentialHeaders() []string
+}
+
+// ErrUnauthenticated is returned by authenticators for invalid credentials.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+type principalContextKey struct{}
+
+// PrincipalFromContext returns the Principal stored in the context by the Server.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+
+	return principal, ok
+}
+
+// ContextWithPrincipal returns a copy of the context holding the Principal.
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// KeyByPrincipal groups requests by the authenticated caller and
+// falls back to KeyByIP for anonymous requests.
+func KeyByPrincipal(r *http.Request) string {
+	if principal, ok := PrincipalFromContext(r.Context()); ok {
+		return principal.Scheme + ":" + principal.ID
+	}
+
+	return KeyByIP(r)
+}
+
+// authRequirement is read from the "auth" metadata of the method.
+// Supported values are "required", "optional", "none" or a list of
+// schemes one of which must authenticate the request.
+type authRequirement struct {
+	required bool
+	schemes  []string
+}
+
+func authRequirementFor(rt *route) (authRequirement, bool) {
+	switch value := rt.metadata["auth"].(type) {
+	case nil:
+		return authRequirement{required: true}, true
+	case string:
+		switch value {
+		case "required":
+			return authRequirement{required: true}, true
+		case "optional":
+			return authRequirement{}, true
+		case "none":
+			return authRequirement{}, false
+		}
+	case []interface{}:
+		requirement := authRequirement{required: true}
+		for i := range value {
+			if scheme, ok := value[i].(string); ok {
+				requirement.schemes = append(requirement.schemes, scheme)
+			}
+		}
+
+		return requirement, true
+	case []string:
+		return authRequirement{required: true, schemes: value}, true
+	}
+
+	return authRequirement{required: true}, true
+}
+
+func (req authRequirement) allows(scheme string) bool {
+	if len(req.schemes) == 0 {
+		return true
+	}
+
+	for i := range req.schemes {
+		if req.schemes[i] == scheme {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authenticate wraps the handler of the route with configured authenticators.
+// Request is authenticated by the first authenticator that accepts it and
+// rejected if none did but some of them found invalid credentials.
+func (s *server) authenticate(rt *route, next http.Handler) http.Handler {
+	if len(s.authenticators) == 0 {
+		return next
+	}
+
+	requirement, ok := authRequirementFor(rt)
+	if !ok {
+		return next
+	}
+
+	rt.authHeaders = s.authHeaders(requirement)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// calls dispatched in-process, e.g. from batches, keep the caller
+		if principal, ok := PrincipalFromContext(r.Context()); ok && requirement.allows(principal.Scheme) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		var invalid bool
+		for i := range s.authenticators {
+			if !requirement.allows(s.authenticators[i].Scheme()) {
+				continue
+			}
+
+			principal, err := s.authenticators[i].Authenticate(r)
+			var clientErr ClientError
+			if errors.As(err, &clientErr) {
+				reportAuthFailure(r)
+				s.OnErr(w, r, clientErr)
+
+				return
+			}
+
+			if err != nil {
+				invalid = true
+
+				continue
+			}
+
+			if principal != nil {
+				principal.Scheme = s.authenticators[i].Scheme()
+				next.ServeHTTP(w, r.WithContext(ContextWithPrincipal(r.Context(), principal)))
+
+				return
+			}
+		}
+
+		if invalid {
+			reportAuthFailure(r)
+			s.OnErr(w, r, ClientError{Code: http.StatusUnauthorized, Message: "invalid credentials"})
+
+			return
+		}
+
+		if requirement.required {
+			s.OnErr(w, r, ClientError{Code: http.StatusUnauthorized, Message: "authentication required"})
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authHeaders returns request headers carrying credentials of the
+// authenticators allowed by the requirement.
+func (s *server) authHeaders(requirement authRequirement) []string {
+	var headers []string
+	seen := make(map[string]bool)
+	for i := range s.authenticators {
+		if !requirement.allows(s.authenticators[i].Scheme()) {
+			continue
+		}
+
+		names := []string{"Authorization"}
+		if a, ok := s.authenticators[i].(credentialHeaders); ok {
+			names = a.credentialHeaders()
+		}
+
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				headers = append(headers, name)
+			}
+		}
+	}
+
+	return headers
+}
+
+type apiKeyAuthenticator struct {
+	header string
+	keys   map[[sha256.Size]byte]string
+}
+
+// NewAPIKeyAuthenticator authenticates requests by static API keys sent in
+// X-API-KEY header. Keys map API keys to principal IDs.
+func NewAPIKeyAuthenticator(keys map[string]string) Authenticator {
+	a := &apiKeyAuthenticator{
+		header: "X-API-KEY",
+		keys:   make(map[[sha256.Size]byte]string, len(keys)),
+	}
+
+	// keys are looked up by their hashes to avoid timing attacks on map lookups
+	for key, id := range keys {
+		a.keys[sha256.Sum256([]byte(key))] = id
+	}
+
+	return a
+}
+
+func (a *apiKeyAuthenticator) Scheme() string { return "apikey" }
+
+func (a *apiKeyAuthenticator) credentialHeaders() []string { return []string{a.header} }
+
+func (a *apiKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(a.header)
+	if key == "" {
+		return nil, nil
+	}
+
+	id, ok := a.keys[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	return &Principal{ID: id}, nil
+}
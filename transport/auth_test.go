@@ -0,0 +1,171 @@
+package transport
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func principalHandler(w http.ResponseWriter, r *http.Request) {
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		w.Write([]byte(`anonymous`))
+		return
+	}
+
+	w.Write([]byte(principal.Scheme + ":" + principal.ID))
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	srv := NewServer(WithAuthenticator(NewAPIKeyAuthenticator(map[string]string{"secret-key": "mobile"})))
+	srv.Register("Service", "Method", principalHandler)
+	srv.Register("Service", "Public", principalHandler, WithMetadata(map[string]interface{}{"auth": "optional"}))
+
+	tests := []struct {
+		path   string
+		key    string
+		status int
+		body   string
+	}{
+		{path: "/Service.Method", key: "secret-key", status: http.StatusOK, body: "apikey:mobile"},
+		{path: "/Service.Method", key: "wrong-key", status: http.StatusUnauthorized, body: `{"error":"invalid credentials"}`},
+		{path: "/Service.Method", status: http.StatusUnauthorized, body: `{"error":"authentication required"}`},
+		{path: "/Service.Public", status: http.StatusOK, body: "anonymous"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, test.path, nil)
+		if test.key != "" {
+			r.Header.Set("X-API-KEY", test.key)
+		}
+
+		srv.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s with key %q: expected %d status code, got %d", test.path, test.key, test.status, w.Code)
+		}
+
+		if w.Body.String() != test.body {
+			t.Errorf("%s with key %q: expected %q response body, got %q", test.path, test.key, test.body, w.Body.String())
+		}
+	}
+}
+
+func TestHMACAuthenticator(t *testing.T) {
+	srv := NewServer(WithAuthenticator(NewHMACAuthenticator(map[string]string{"key": "secret"})))
+	srv.Register("Service", "Method", principalHandler)
+	srv.Register("Service", "Other", principalHandler)
+
+	body := `{"name":"Mat"}`
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	sign := func(nonce string) string {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte("POST\n/Service.Method\n\n" + timestamp + "\n" + nonce + "\n" + body))
+
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		path     string
+		nonce    string
+		expected int
+	}{
+		{path: "/Service.Method", nonce: "first", expected: http.StatusOK},
+		{path: "/Service.Method", nonce: "first", expected: http.StatusUnauthorized},
+		{path: "/Service.Other", nonce: "second", expected: http.StatusUnauthorized},
+	}
+
+	for i, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(body))
+		r.Header.Set("X-API-KEY", "key")
+		r.Header.Set("X-API-TIMESTAMP", timestamp)
+		r.Header.Set("X-API-NONCE", test.nonce)
+		r.Header.Set("X-API-SIGNATURE", sign(test.nonce))
+		srv.ServeHTTP(w, r)
+		if w.Code != test.expected {
+			t.Errorf("request %d to %s: expected %d status code, got %d", i, test.path, test.expected, w.Code)
+		}
+	}
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	file, err := os.CreateTemp("", "jwks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(jwks); err != nil {
+		t.Fatal(err)
+	}
+
+	file.Close()
+
+	authenticator, err := NewJWTAuthenticator(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := NewServer(WithAuthenticator(authenticator))
+	srv.Register("Service", "Method", principalHandler)
+
+	sign := func(claims map[string]interface{}) string {
+		header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test"})
+		payload, _ := json.Marshal(claims)
+		signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+		digest := sha256.Sum256([]byte(signed))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+
+	tests := []struct {
+		token  string
+		status int
+	}{
+		{token: sign(map[string]interface{}{"sub": "user", "exp": time.Now().Add(time.Hour).Unix()}), status: http.StatusOK},
+		{token: sign(map[string]interface{}{"sub": "user", "exp": time.Now().Add(-time.Hour).Unix()}), status: http.StatusUnauthorized},
+		{token: "not.a.token", status: http.StatusUnauthorized},
+	}
+
+	for i, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Service.Method", nil)
+		r.Header.Set("Authorization", "Bearer "+test.token)
+		srv.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("token %d: expected %d status code, got %d", i, test.status, w.Code)
+		}
+	}
+}
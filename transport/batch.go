@@ -0,0 +1,97 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// BatchService and BatchMethod name the route executing multiple calls in
+// a single request, by default at POST /gorpc.Batch. It accepts a list of
+// {"service": "GreeterService", "method": "Greet", "body": {...}} calls and
+// responds with a list of {"status": 200, "body": {...}} results in the
+// same order.
+const (
+	BatchService = "gorpc"
+	BatchMethod  = "Batch"
+)
+
+type batchCall struct {
+	Service string          `json:"service"`
+	Method  string          `json:"method"`
+	Body    json.RawMessage `json:"body"`
+}
+
+type batchResult struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// registerBatch registers the batch route dispatching up to concurrency
+// calls at once. Calls go through the same handlers, middleware and limits
+// as separate requests. Batch can be called anonymously, each call is then
+// authenticated on its own.
+func (s *server) registerBatch(concurrency int) {
+	s.Register(BatchService, BatchMethod, func(w http.ResponseWriter, r *http.Request) {
+		var calls []batchCall
+		if err := Decode(r, &calls); err != nil {
+			s.OnErr(w, r, err)
+
+			return
+		}
+
+		results := make([]batchResult, len(calls))
+		slots := make(chan struct{}, concurrency)
+
+		var wg sync.WaitGroup
+		for i := range calls {
+			if calls[i].Service == BatchService && calls[i].Method == BatchMethod {
+				results[i] = batchError(http.StatusBadRequest, "nested batches are not supported")
+
+				continue
+			}
+
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(i int) {
+				defer func() {
+					// panics escaping the method can't be recovered by net/http in this goroutine
+					if value := recover(); value != nil {
+						results[i] = batchError(http.StatusInternalServerError, "internal server error")
+					}
+
+					<-slots
+					wg.Done()
+				}()
+
+				body := calls[i].Body
+				if len(body) == 0 {
+					body = json.RawMessage("{}")
+				}
+
+				results[i] = batchResultOf(s.dispatch(r, calls[i].Service, calls[i].Method, body))
+			}(i)
+		}
+
+		wg.Wait()
+
+		if err := Encode(w, r, http.StatusOK, results); err != nil {
+			s.OnErr(w, r, err)
+		}
+	}, WithMetadata(map[string]interface{}{"auth": "optional"}))
+}
+
+func batchResultOf(response *responseBuffer) batchResult {
+	body := response.body.Bytes()
+	if !json.Valid(body) {
+		return batchError(http.StatusInternalServerError, "invalid JSON response")
+	}
+
+	return batchResult{Status: response.Status(), Body: body}
+}
+
+func batchError(status int, message string) batchResult {
+	body, _ := json.Marshal(ClientError{Code: status, Message: message})
+
+	return batchResult{Status: status, Body: body}
+}
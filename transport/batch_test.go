@@ -0,0 +1,36 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithBatch(t *testing.T) {
+	srv := NewServer(WithBatch(2), WithAuthenticator(NewAPIKeyAuthenticator(map[string]string{"key": "id"})))
+	srv.Register("Service", "Greet", greetHandler(srv))
+
+	body := `[
+		{"service": "Service", "method": "Greet", "body": {"name": "Mat"}},
+		{"service": "Service", "method": "Unknown"},
+		{"service": "gorpc", "method": "Batch", "body": []}
+	]`
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/gorpc.Batch", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-API-KEY", "key")
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d status code, got %d", http.StatusOK, w.Code)
+	}
+
+	expected := `[` +
+		`{"status":200,"body":{"greeting":"Hello Mat from id"}},` +
+		`{"status":404,"body":{"error":"not found"}},` +
+		`{"status":400,"body":{"error":"nested batches are not supported"}}` +
+		`]`
+	if w.Body.String() != expected {
+		t.Errorf("expected %q response body, got %q", expected, w.Body.String())
+	}
+}
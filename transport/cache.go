@@ -0,0 +1,116 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// QueryParam is the query string parameter holding JSON encoded request
+// of GET calls to idempotent methods, e.g. GET /Service.Method?request={"id":1}.
+const QueryParam = "request"
+
+const defaultCacheControl = "no-cache"
+
+type routeContextKey struct{}
+
+func routeFromContext(ctx context.Context) (*route, bool) {
+	rt, ok := ctx.Value(routeContextKey{}).(*route)
+
+	return rt, ok
+}
+
+// idempotent reports whether the method is marked with "idempotent: true"
+// metadata and can be called with GET requests.
+func (rt *route) idempotent() bool {
+	idempotent, ok := rt.metadata["idempotent"].(bool)
+
+	return ok && idempotent
+}
+
+// queryBody returns JSON request of GET call from the query string.
+func queryBody(r *http.Request) ([]byte, error) {
+	body := []byte(r.URL.Query().Get(QueryParam))
+	if len(body) == 0 {
+		body = []byte("{}")
+	}
+
+	if err := checkLimits(body, limitsFromContext(r.Context())); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// cacheable sets ETag and Cache-Control headers for successful GET calls to
+// idempotent methods. Responses to authenticated calls are private and vary
+// by the credential headers. ETag is weak, because it is shared by the
+// compressed and identity responses. It reports false if the client has
+// fresh response and 304 Not Modified has been written instead.
+func cacheable(w http.ResponseWriter, r *http.Request, status int, body []byte) bool {
+	if r.Method != http.MethodGet || status != http.StatusOK {
+		return true
+	}
+
+	rt, ok := routeFromContext(r.Context())
+	if !ok || !rt.idempotent() {
+		return true
+	}
+
+	cacheControl, ok := metadataString(rt.metadata, "cacheControl")
+	if !ok {
+		cacheControl = defaultCacheControl
+	}
+
+	if _, ok := PrincipalFromContext(r.Context()); ok {
+		cacheControl = privateCacheControl(cacheControl)
+		for _, header := range rt.authHeaders {
+			w.Header().Add("Vary", header)
+		}
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", "W/"+etag)
+	w.Header().Set("Cache-Control", cacheControl)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+
+		return false
+	}
+
+	return true
+}
+
+// privateCacheControl replaces public and private directives of
+// Cache-Control with private.
+func privateCacheControl(cacheControl string) string {
+	directives := []string{"private"}
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.TrimSpace(directive)
+		name := strings.ToLower(strings.SplitN(directive, "=", 2)[0])
+		if directive == "" || name == "public" || name == "private" {
+			continue
+		}
+
+		directives = append(directives, directive)
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// etagMatches compares the entity tags with weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+
+	return false
+}
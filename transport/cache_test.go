@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestIdempotentGet(t *testing.T) {
+	srv := NewServer()
+	handler := greetHandler(srv)
+	srv.Register("Service", "Lookup", handler, WithMetadata(map[string]interface{}{
+		"idempotent":   true,
+		"cacheControl": "public, max-age=60",
+	}))
+	srv.Register("Service", "Method", handler)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/Service.Lookup?request="+url.QueryEscape(`{"name":"Mat"}`), nil)
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d status code, got %d", http.StatusOK, w.Code)
+	}
+
+	expected := `{"greeting":"Hello Mat"}`
+	if w.Body.String() != expected {
+		t.Errorf("expected %q response body, got %q", expected, w.Body.String())
+	}
+
+	if w.Header().Get("Cache-Control") != "public, max-age=60" {
+		t.Errorf("expected %q cache control, got %q", "public, max-age=60", w.Header().Get("Cache-Control"))
+	}
+
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag to be set")
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/Service.Lookup?request="+url.QueryEscape(`{"name":"Mat"}`), nil)
+	r.Header.Set("If-None-Match", etag)
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected %d status code, got %d", http.StatusNotModified, w.Code)
+	}
+
+	if w.Body.Len() != 0 {
+		t.Errorf("expected empty response body, got %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/Service.Method", nil))
+	if w.Code == http.StatusOK {
+		t.Errorf("expected GET to be rejected for not idempotent method")
+	}
+}
+
+func TestIdempotentGetAuthenticated(t *testing.T) {
+	srv := NewServer(WithAuthenticator(NewAPIKeyAuthenticator(map[string]string{"secret": "mat"})))
+	srv.Register("Service", "Lookup", func(w http.ResponseWriter, r *http.Request) {
+		_ = Encode(w, r, http.StatusOK, map[string]string{"greeting": "Hello"})
+	}, WithMetadata(map[string]interface{}{
+		"idempotent":   true,
+		"cacheControl": "public, max-age=60",
+	}))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/Service.Lookup", nil)
+	r.Header.Set("X-API-KEY", "secret")
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d status code, got %d", http.StatusOK, w.Code)
+	}
+
+	if w.Header().Get("Cache-Control") != "private, max-age=60" {
+		t.Errorf("expected %q cache control, got %q", "private, max-age=60", w.Header().Get("Cache-Control"))
+	}
+
+	vary := strings.Join(w.Header().Values("Vary"), ", ")
+	if !strings.Contains(vary, "X-API-KEY") {
+		t.Errorf("expected response to vary by X-API-KEY, got %q", vary)
+	}
+
+	if !strings.HasPrefix(w.Header().Get("ETag"), `W/"`) {
+		t.Errorf("expected weak ETag, got %q", w.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,191 @@
+package transport
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const defaultCompressionThreshold = 1024
+
+// Compressor implements a HTTP content coding, e.g. gzip. Implement it to
+// plug in other algorithms such as zstd or brotli.
+type Compressor interface {
+	// Encoding is the name of the content coding used in
+	// Accept-Encoding and Content-Encoding headers.
+	Encoding() string
+	NewWriter(w io.Writer) io.WriteCloser
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// compression is stored in the request context for Encode and Decode.
+type compression struct {
+	compressors []Compressor
+	threshold   int
+}
+
+var defaultCompression = &compression{
+	compressors: []Compressor{NewGzipCompressor(gzip.DefaultCompression), NewDeflateCompressor(zlib.DefaultCompression)},
+	threshold:   defaultCompressionThreshold,
+}
+
+type compressionContextKey struct{}
+
+func compressionFromContext(ctx context.Context) *compression {
+	c, ok := ctx.Value(compressionContextKey{}).(*compression)
+	if !ok {
+		return defaultCompression
+	}
+
+	return c
+}
+
+func (c *compression) lookup(encoding string) Compressor {
+	for i := range c.compressors {
+		if strings.EqualFold(c.compressors[i].Encoding(), encoding) {
+			return c.compressors[i]
+		}
+	}
+
+	return nil
+}
+
+// negotiate picks the compressor with the highest quality value in
+// Accept-Encoding header. Ties are resolved by the order of compressors.
+// It returns nil if the response should not be compressed.
+func (c *compression) negotiate(acceptEncoding string) Compressor {
+	if acceptEncoding == "" {
+		return nil
+	}
+
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, quality := parseQuality(part)
+		if coding != "" {
+			qualities[coding] = quality
+		}
+	}
+
+	var best Compressor
+	var bestQuality float64
+	for i := range c.compressors {
+		quality, ok := qualities[strings.ToLower(c.compressors[i].Encoding())]
+		if !ok {
+			quality = qualities["*"]
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = c.compressors[i], quality
+		}
+	}
+
+	if identity, ok := qualities["identity"]; ok && identity > bestQuality {
+		return nil
+	}
+
+	return best
+}
+
+// parseQuality parses "gzip;q=0.5" into lower cased coding and its quality.
+func parseQuality(s string) (string, float64) {
+	params := strings.Split(s, ";")
+	coding, quality := strings.ToLower(strings.TrimSpace(params[0])), 1.0
+
+	for _, param := range params[1:] {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "q=") {
+			continue
+		}
+
+		q, err := strconv.ParseFloat(param[2:], 64)
+		if err != nil {
+			return "", 0
+		}
+
+		quality = q
+	}
+
+	return coding, quality
+}
+
+type gzipCompressor struct {
+	level   int
+	writers sync.Pool
+}
+
+// NewGzipCompressor makes gzip Compressor with pooled writers
+// of the given compression level.
+func NewGzipCompressor(level int) Compressor {
+	return &gzipCompressor{level: level}
+}
+
+func (c *gzipCompressor) Encoding() string { return "gzip" }
+
+func (c *gzipCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	gzw, ok := c.writers.Get().(*gzip.Writer)
+	if ok {
+		gzw.Reset(w)
+	} else {
+		var err error
+		if gzw, err = gzip.NewWriterLevel(w, c.level); err != nil {
+			gzw = gzip.NewWriter(w)
+		}
+	}
+
+	return &pooledWriter{WriteCloser: gzw, release: func() { c.writers.Put(gzw) }}
+}
+
+func (c *gzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+type deflateCompressor struct {
+	level   int
+	writers sync.Pool
+}
+
+// NewDeflateCompressor makes Compressor of "deflate" content coding,
+// which is zlib format, with pooled writers of the given compression level.
+func NewDeflateCompressor(level int) Compressor {
+	return &deflateCompressor{level: level}
+}
+
+func (c *deflateCompressor) Encoding() string { return "deflate" }
+
+func (c *deflateCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	zw, ok := c.writers.Get().(*zlib.Writer)
+	if ok {
+		zw.Reset(w)
+	} else {
+		var err error
+		if zw, err = zlib.NewWriterLevel(w, c.level); err != nil {
+			zw = zlib.NewWriter(w)
+		}
+	}
+
+	return &pooledWriter{WriteCloser: zw, release: func() { c.writers.Put(zw) }}
+}
+
+func (c *deflateCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+// pooledWriter returns the underlying writer to its pool once closed.
+type pooledWriter struct {
+	io.WriteCloser
+	release func()
+}
+
+func (w *pooledWriter) Close() error {
+	err := w.WriteCloser.Close()
+	if w.release != nil {
+		w.release()
+		w.release = nil
+	}
+
+	return err
+}
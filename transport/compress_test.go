@@ -0,0 +1,106 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressionNegotiate(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{acceptEncoding: "", expected: ""},
+		{acceptEncoding: "gzip", expected: "gzip"},
+		{acceptEncoding: "deflate, gzip", expected: "gzip"},
+		{acceptEncoding: "gzip;q=0.5, deflate", expected: "deflate"},
+		{acceptEncoding: "gzip;q=0, br", expected: ""},
+		{acceptEncoding: "*", expected: "gzip"},
+		{acceptEncoding: "gzip;q=0.5, identity", expected: ""},
+	}
+
+	for _, test := range tests {
+		var encoding string
+		if compressor := defaultCompression.negotiate(test.acceptEncoding); compressor != nil {
+			encoding = compressor.Encoding()
+		}
+
+		if encoding != test.expected {
+			t.Errorf("%q: expected %q encoding, got %q", test.acceptEncoding, test.expected, encoding)
+		}
+	}
+}
+
+func TestEncodeCompressionThreshold(t *testing.T) {
+	srv := NewServer(WithCompressionThreshold(32))
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		_ = Encode(w, r, http.StatusOK, map[string]string{"greeting": r.URL.Query().Get("greeting")})
+	})
+
+	tests := []struct {
+		greeting string
+		encoding string
+	}{
+		{greeting: "Hi", encoding: ""},
+		{greeting: strings.Repeat("Hi there ", 10), encoding: "gzip"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Service.Method?greeting="+strings.Replace(test.greeting, " ", "+", -1), nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		srv.ServeHTTP(w, r)
+		if w.Header().Get("Content-Encoding") != test.encoding {
+			t.Errorf("expected %q content encoding, got %q", test.encoding, w.Header().Get("Content-Encoding"))
+		}
+	}
+}
+
+func TestDecodeCompressedBody(t *testing.T) {
+	srv := NewServer()
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Name string
+		}
+		if err := Decode(r, &request); err != nil {
+			srv.OnErr(w, r, err)
+			return
+		}
+
+		w.Write([]byte(request.Name))
+	})
+
+	var body bytes.Buffer
+	gzw := gzip.NewWriter(&body)
+	gzw.Write([]byte(`{"name":"Mat"}`))
+	gzw.Close()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/Service.Method", &body)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Encoding", "gzip")
+	srv.ServeHTTP(w, r)
+	if w.Body.String() != "Mat" {
+		t.Errorf("expected %q response body, got %q", "Mat", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/Service.Method", strings.NewReader(`{"name":"Mat"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Encoding", "br")
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected %d status code, got %d", http.StatusUnsupportedMediaType, w.Code)
+	}
+
+	content, _ := io.ReadAll(w.Body)
+	expected := `{"error":"unsupported content encoding"}`
+	if string(content) != expected {
+		t.Errorf("expected %q response body, got %q", expected, string(content))
+	}
+}
@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures Cross-Origin Resource Sharing.
+type CORSOptions struct {
+	// AllowedOrigins lists origins allowed to call the server. "*" allows any origin.
+	AllowedOrigins []string
+	// AllowedHeaders lists request headers clients may send, Content-Type
+	// is always allowed. "*" allows any requested header.
+	AllowedHeaders []string
+	// ExposedHeaders lists response headers available to the clients.
+	ExposedHeaders []string
+	// AllowCredentials allows requests with cookies and HTTP authentication.
+	// It can't be combined with "*" in AllowedOrigins.
+	AllowCredentials bool
+	// MaxAge is how long the results of preflight request can be cached.
+	MaxAge time.Duration
+}
+
+func (c *CORSOptions) allowsOrigin(origin string) bool {
+	for i := range c.AllowedOrigins {
+		if c.AllowedOrigins[i] == "*" || strings.EqualFold(c.AllowedOrigins[i], origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *CORSOptions) allowsAnyOrigin() bool {
+	for i := range c.AllowedOrigins {
+		if c.AllowedOrigins[i] == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setOrigin sets CORS headers shared by preflight and actual requests.
+// It reports false if the origin is not allowed.
+func (c *CORSOptions) setOrigin(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	w.Header().Add("Vary", "Origin")
+	if origin == "" || !c.allowsOrigin(origin) {
+		return false
+	}
+
+	if len(c.AllowedOrigins) == 1 && c.AllowedOrigins[0] == "*" {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if c.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	return true
+}
+
+func (c *CORSOptions) preflight(w http.ResponseWriter, r *http.Request, allow string) {
+	if !c.setOrigin(w, r) {
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Methods", allow)
+
+	headers := "Content-Type"
+	if len(c.AllowedHeaders) == 1 && c.AllowedHeaders[0] == "*" {
+		if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+			headers = requested
+		}
+	} else if len(c.AllowedHeaders) > 0 {
+		headers += ", " + strings.Join(c.AllowedHeaders, ", ")
+	}
+
+	w.Header().Set("Access-Control-Allow-Headers", headers)
+
+	if c.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+	}
+}
+
+func (c *CORSOptions) actual(w http.ResponseWriter, r *http.Request) {
+	if c.setOrigin(w, r) && len(c.ExposedHeaders) > 0 {
+		w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+	}
+}
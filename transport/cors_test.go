@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMethodNotAllowed(t *testing.T) {
+	srv := NewServer()
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/Service.Method", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected %d status code, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+
+	if w.Header().Get("Allow") != "POST, OPTIONS" {
+		t.Errorf("expected %q Allow header, got %q", "POST, OPTIONS", w.Header().Get("Allow"))
+	}
+
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/Service.Unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected %d status code, got %d", http.StatusNotFound, w.Code)
+	}
+
+	expected := `{"error":"not found"}`
+	if w.Body.String() != expected {
+		t.Errorf("expected %q response body, got %q", expected, w.Body.String())
+	}
+}
+
+func TestWithCORS(t *testing.T) {
+	srv := NewServer(WithCORS(CORSOptions{
+		AllowedOrigins:   []string{"https://example.com"},
+		AllowedHeaders:   []string{"Authorization"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}))
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodOptions, "/Service.Method", nil)
+	r.Header.Set("Origin", "https://example.com")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected %d status code, got %d", http.StatusNoContent, w.Code)
+	}
+
+	headers := map[string]string{
+		"Access-Control-Allow-Origin":      "https://example.com",
+		"Access-Control-Allow-Methods":     "POST, OPTIONS",
+		"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age":           "3600",
+	}
+	for header, expected := range headers {
+		if w.Header().Get(header) != expected {
+			t.Errorf("expected %s header to be %q, got %q", header, expected, w.Header().Get(header))
+		}
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/Service.Method", nil)
+	r.Header.Set("Origin", "https://evil.com")
+	srv.ServeHTTP(w, r)
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("expected origin to be rejected, got %q", w.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestWithCORSCredentialsForAnyOrigin(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected credentials for any origin to be rejected")
+		}
+	}()
+
+	WithCORS(CORSOptions{AllowedOrigins: []string{"https://example.com", "*"}, AllowCredentials: true})
+}
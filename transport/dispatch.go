@@ -0,0 +1,78 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+)
+
+type dispatchContextKey struct{}
+
+// isDispatched reports whether the request is an in-memory call made by
+// dispatch, whose status code is read by the caller.
+func isDispatched(ctx context.Context) bool {
+	dispatched, _ := ctx.Value(dispatchContextKey{}).(bool)
+
+	return dispatched
+}
+
+// dispatch calls the method of the server with the body in-memory, reusing
+// the context and headers of the parent request, and returns the response.
+// Responses are not compressed, principal of the parent request is kept.
+func (s *server) dispatch(parent *http.Request, service, method string, body []byte) *responseBuffer {
+	ctx := context.WithValue(parent.Context(), dispatchContextKey{}, true)
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, s.pathFn(service, method), bytes.NewReader(body))
+	if err != nil {
+		w := newResponseBuffer()
+		s.OnErr(w, parent.WithContext(ctx), err)
+
+		return w
+	}
+
+	r.RemoteAddr = parent.RemoteAddr
+	r.Header = parent.Header.Clone()
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Del("Accept-Encoding")
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+
+	w := newResponseBuffer()
+	s.ServeHTTP(w, r)
+
+	return w
+}
+
+// responseBuffer is http.ResponseWriter keeping the response in memory.
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseBuffer() *responseBuffer {
+	return &responseBuffer{header: make(http.Header)}
+}
+
+func (b *responseBuffer) Header() http.Header { return b.header }
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+
+	return b.body.Write(p)
+}
+
+func (b *responseBuffer) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *responseBuffer) Status() int {
+	if b.status == 0 {
+		return http.StatusOK
+	}
+
+	return b.status
+}
@@ -4,21 +4,52 @@ import (
 	"net/http"
 )
 
-var DefaultErrorHandler ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-	clientErr, ok := err.(ClientError)
-	if ok {
-		_ = Encode(w, r, clientErr.Code, clientErr)
-		return
-	}
-
-	_ = Encode(w, r, http.StatusInternalServerError, map[string]string{"message": "internal server error"})
-}
+// DefaultErrorHandler responds with ClientError, possibly wrapped, and maps
+// decode errors and context errors (see ErrorRegistry). Other errors
+// become internal server errors.
+var DefaultErrorHandler ErrorHandler = NewErrorRegistry().ErrorHandler()
 
 type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
 
+// ErrorMode selects the status code of error responses.
+type ErrorMode int
+
+const (
+	// StatusCodeErrors writes errors with their status codes. It is the default.
+	StatusCodeErrors ErrorMode = iota
+	// EnvelopeErrors writes errors with 200 status code, so clients tell them
+	// from successful responses by the "error" field only. Twirp calls and
+	// calls within batches keep their status codes.
+	EnvelopeErrors
+)
+
+// envelopeWriter replaces status code of the response with 200.
+type envelopeWriter struct {
+	http.ResponseWriter
+}
+
+func (w envelopeWriter) WriteHeader(int) {
+	w.ResponseWriter.WriteHeader(http.StatusOK)
+}
+
 type ClientError struct {
 	Code    int    `json:"-"`
 	Message string `json:"error"`
+	// ErrorCode is an optional machine readable code of the error.
+	ErrorCode string `json:"code,omitempty"`
+	// RequestID is set by DefaultErrorHandler when the server assigns
+	// request IDs (see WithRequestID).
+	RequestID string `json:"requestId,omitempty"`
 }
 
 func (e ClientError) Error() string { return e.Message }
+
+// DecodeError is returned by Decode when the request body is not valid
+// JSON or does not match the request type.
+type DecodeError struct {
+	Err error
+}
+
+func (e DecodeError) Error() string { return "decode request body: " + e.Err.Error() }
+
+func (e DecodeError) Unwrap() error { return e.Err }
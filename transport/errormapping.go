@@ -0,0 +1,142 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+// StatusClientClosedRequest is the non-standard status code of calls
+// canceled by the client.
+const StatusClientClosedRequest = 499
+
+// ErrorMapping describes the client error an error is mapped to.
+type ErrorMapping struct {
+	Status int
+	// Code is an optional machine readable code of the error.
+	Code string
+	// Message defaults to the message of the matched error.
+	Message string
+}
+
+// ErrorRegistry maps errors returned by methods to client errors. Wrapped
+// ClientError is used as is, other errors are matched against the registered
+// mappings in order of registration, then against the built-in ones:
+// DecodeError maps to 400, context.Canceled to 499 and
+// context.DeadlineExceeded to 504 status code.
+//
+// Register mappings before the server starts to handle requests.
+type ErrorRegistry struct {
+	rules []errorRule
+}
+
+type errorRule struct {
+	// match returns the matched error from the chain of err.
+	match   func(err error) (error, bool)
+	mapping ErrorMapping
+}
+
+// builtinErrors are matched after the registered mappings.
+var builtinErrors = func() *ErrorRegistry {
+	reg := &ErrorRegistry{}
+	reg.As(DecodeError{}, ErrorMapping{Status: http.StatusBadRequest})
+	reg.Is(context.Canceled, ErrorMapping{Status: StatusClientClosedRequest, Message: "request canceled"})
+	reg.Is(context.DeadlineExceeded, ErrorMapping{Status: http.StatusGatewayTimeout, Message: "deadline exceeded"})
+
+	return reg
+}()
+
+func NewErrorRegistry() *ErrorRegistry {
+	return &ErrorRegistry{}
+}
+
+// Is maps errors matching the target with errors.Is, e.g. sentinel errors.
+func (reg *ErrorRegistry) Is(target error, mapping ErrorMapping) {
+	reg.rules = append(reg.rules, errorRule{
+		match: func(err error) (error, bool) {
+			return target, errors.Is(err, target)
+		},
+		mapping: mapping,
+	})
+}
+
+// As maps errors of the target's type with errors.As. Target is any value
+// of the type, e.g. &NotFoundError{}.
+func (reg *ErrorRegistry) As(target error, mapping ErrorMapping) {
+	if target == nil {
+		panic("transport: nil error type target")
+	}
+
+	typ := reflect.TypeOf(target)
+	reg.rules = append(reg.rules, errorRule{
+		match: func(err error) (error, bool) {
+			matched := reflect.New(typ)
+			if !errors.As(err, matched.Interface()) {
+				return nil, false
+			}
+
+			return matched.Elem().Interface().(error), true
+		},
+		mapping: mapping,
+	})
+}
+
+// ClientError returns the client error err maps to. It reports false for
+// errors that should be responded to with internal server error, panics
+// of methods included.
+func (reg *ErrorRegistry) ClientError(err error) (ClientError, bool) {
+	var panicErr PanicError
+	if errors.As(err, &panicErr) {
+		return ClientError{}, false
+	}
+
+	var clientErr ClientError
+	if errors.As(err, &clientErr) {
+		return clientErr, true
+	}
+
+	for _, rules := range [][]errorRule{reg.rules, builtinErrors.rules} {
+		for i := range rules {
+			if matched, ok := rules[i].match(err); ok {
+				return rules[i].mapping.clientError(matched), true
+			}
+		}
+	}
+
+	return ClientError{}, false
+}
+
+func (m ErrorMapping) clientError(err error) ClientError {
+	message := m.Message
+	if message == "" {
+		message = err.Error()
+	}
+
+	return ClientError{Code: m.Status, Message: message, ErrorCode: m.Code}
+}
+
+// ErrorHandler returns ErrorHandler writing client errors mapped by the
+// registry and internal server error for the rest. Both are written as
+// {"error": "..."} objects.
+func (reg *ErrorRegistry) ErrorHandler() ErrorHandler {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		requestID := RequestIDFromContext(r.Context())
+
+		clientErr, ok := reg.ClientError(err)
+		if ok {
+			if clientErr.RequestID == "" {
+				clientErr.RequestID = requestID
+			}
+
+			_ = Encode(w, r, clientErr.Code, clientErr)
+			return
+		}
+
+		_ = Encode(w, r, http.StatusInternalServerError, ClientError{
+			Code:      http.StatusInternalServerError,
+			Message:   "internal server error",
+			RequestID: requestID,
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var errGreetingNotFound = errors.New("greeting not found")
+
+type quotaError struct {
+	Limit int
+}
+
+func (e *quotaError) Error() string { return fmt.Sprintf("quota of %d exceeded", e.Limit) }
+
+func TestErrorRegistry(t *testing.T) {
+	registry := NewErrorRegistry()
+	registry.Is(errGreetingNotFound, ErrorMapping{Status: http.StatusNotFound, Code: "greeting_not_found"})
+	registry.As(&quotaError{}, ErrorMapping{Status: http.StatusTooManyRequests, Message: "quota exceeded"})
+
+	tests := []struct {
+		err      error
+		status   int
+		expected string
+	}{
+		{
+			err:      fmt.Errorf("get greeting: %w", errGreetingNotFound),
+			status:   http.StatusNotFound,
+			expected: `{"error":"greeting not found","code":"greeting_not_found"}`,
+		},
+		{
+			err:      fmt.Errorf("greet: %w", &quotaError{Limit: 10}),
+			status:   http.StatusTooManyRequests,
+			expected: `{"error":"quota exceeded"}`,
+		},
+		{
+			err:      fmt.Errorf("greet: %w", ClientError{Code: http.StatusConflict, Message: "already greeted"}),
+			status:   http.StatusConflict,
+			expected: `{"error":"already greeted"}`,
+		},
+		{
+			err:      fmt.Errorf("greet: %w", context.Canceled),
+			status:   StatusClientClosedRequest,
+			expected: `{"error":"request canceled"}`,
+		},
+		{
+			err:      fmt.Errorf("greet: %w", context.DeadlineExceeded),
+			status:   http.StatusGatewayTimeout,
+			expected: `{"error":"deadline exceeded"}`,
+		},
+		{
+			err:      PanicError{Value: errGreetingNotFound},
+			status:   http.StatusInternalServerError,
+			expected: `{"error":"internal server error"}`,
+		},
+		{
+			err:      errors.New("database is down"),
+			status:   http.StatusInternalServerError,
+			expected: `{"error":"internal server error"}`,
+		},
+	}
+
+	handler := registry.ErrorHandler()
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Service.Method", nil)
+		handler(w, r, test.err)
+		if w.Code != test.status {
+			t.Errorf("%v: expected %d status code, got %d", test.err, test.status, w.Code)
+		}
+
+		if w.Body.String() != test.expected {
+			t.Errorf("%v: expected %q response body, got %q", test.err, test.expected, w.Body.String())
+		}
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	srv := NewServer()
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Name string
+		}
+		if err := Decode(r, &request); err != nil {
+			srv.OnErr(w, r, err)
+			return
+		}
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/Service.Method", strings.NewReader(`{"name":1}`))
+	r.Header.Set("Content-Type", "application/json")
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d status code, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	if !strings.HasPrefix(w.Body.String(), `{"error":"decode request body: `) {
+		t.Errorf("expected decode error in response body, got %q", w.Body.String())
+	}
+}
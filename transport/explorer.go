@@ -0,0 +1,23 @@
+package transport
+
+import (
+	"net/http"
+)
+
+// ExplorerService and ExplorerMethod name the route serving the API
+// explorer page, by default at GET /gorpc.Explorer. The page calls
+// methods with paths relative to its own.
+const (
+	ExplorerService = "gorpc"
+	ExplorerMethod  = "Explorer"
+)
+
+// registerExplorer registers the route serving the explorer page.
+func (s *server) registerExplorer(page []byte) {
+	s.Register(ExplorerService, ExplorerMethod, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; style-src 'unsafe-inline'; script-src 'unsafe-inline'")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(page)
+	}, WithMetadata(map[string]interface{}{"idempotent": true, "auth": "none"}))
+}
@@ -0,0 +1,21 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithExplorer(t *testing.T) {
+	srv := NewServer(WithExplorer([]byte(`<html></html>`)))
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/gorpc.Explorer", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected %d status code, got %d", http.StatusOK, w.Code)
+	}
+
+	if w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("expected %q content type, got %q", "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	}
+}
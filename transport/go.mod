@@ -1,5 +1,5 @@
 module github.com/damejeras/gorpc/transport
 
-go 1.13
+go 1.21
 
 require github.com/pkg/errors v0.9.1
@@ -0,0 +1,37 @@
+package transport
+
+import (
+	"net/http"
+)
+
+type greetRequest struct {
+	Name string `json:"name"`
+}
+
+type greetResponse struct {
+	Greeting string `json:"greeting"`
+}
+
+// greetHandler is the Service.Greet method shared by the tests. It greets the
+// name of the request and the principal of authenticated calls.
+func greetHandler(srv Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request greetRequest
+		if err := Decode(r, &request); err != nil {
+			srv.OnErr(w, r, err)
+			return
+		}
+
+		if request.Name == "" {
+			srv.OnErr(w, r, ClientError{Code: http.StatusBadRequest, Message: "name is required"})
+			return
+		}
+
+		response := greetResponse{Greeting: "Hello " + request.Name}
+		if principal, ok := PrincipalFromContext(r.Context()); ok {
+			response.Greeting += " from " + principal.ID
+		}
+
+		_ = Encode(w, r, http.StatusOK, &response)
+	}
+}
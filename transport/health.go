@@ -0,0 +1,212 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// ServingStatus is the status of a service reported by health checks.
+type ServingStatus string
+
+const (
+	StatusUnknown        ServingStatus = "UNKNOWN"
+	StatusServing        ServingStatus = "SERVING"
+	StatusNotServing     ServingStatus = "NOT_SERVING"
+	StatusServiceUnknown ServingStatus = "SERVICE_UNKNOWN"
+)
+
+// HealthService names the health checking routes:
+//   - POST or GET /gorpc.Health.Check reports status of the service given
+//     as {"service": "GreeterService"} request, empty name stands for the server;
+//   - GET /gorpc.Health.Live is the liveness probe;
+//   - GET /gorpc.Health.Ready is the readiness probe, failing while the
+//     server is not serving or is draining.
+const HealthService = "gorpc.Health"
+
+// Health holds serving status of the server and its services, which can be
+// changed at runtime, e.g. when a dependency becomes unavailable.
+type Health struct {
+	mu       sync.RWMutex
+	statuses map[string]ServingStatus
+}
+
+// NewHealth makes Health with the server marked as serving.
+func NewHealth() *Health {
+	return &Health{statuses: map[string]ServingStatus{"": StatusServing}}
+}
+
+// SetServingStatus sets status of the service. Empty service name sets
+// the status of the whole server.
+func (h *Health) SetServingStatus(service string, status ServingStatus) {
+	h.mu.Lock()
+	h.statuses[service] = status
+	h.mu.Unlock()
+}
+
+// Status returns the status of the service and whether it has been set.
+func (h *Health) Status(service string) (ServingStatus, bool) {
+	h.mu.RLock()
+	status, ok := h.statuses[service]
+	h.mu.RUnlock()
+
+	return status, ok
+}
+
+// Shutdown marks the server and all services as not serving.
+func (h *Health) Shutdown() {
+	h.mu.Lock()
+	for service := range h.statuses {
+		h.statuses[service] = StatusNotServing
+	}
+
+	h.statuses[""] = StatusNotServing
+	h.mu.Unlock()
+}
+
+type healthCheckRequest struct {
+	Service string `json:"service"`
+}
+
+type healthCheckResponse struct {
+	Status ServingStatus `json:"status"`
+}
+
+// registerHealth registers health checking routes, which are served
+// while the server is draining.
+func (s *server) registerHealth(health *Health) {
+	metadata := WithMetadata(map[string]interface{}{"idempotent": true, "auth": "none"})
+
+	s.Register(HealthService, "Check", func(w http.ResponseWriter, r *http.Request) {
+		var request healthCheckRequest
+		if err := Decode(r, &request); err != nil {
+			s.OnErr(w, r, err)
+
+			return
+		}
+
+		status, ok := health.Status(request.Service)
+		if !ok {
+			status = StatusServiceUnknown
+			if s.hasService(request.Service) {
+				status, _ = health.Status("")
+			}
+		}
+
+		if status == StatusServiceUnknown {
+			s.OnErr(w, r, ClientError{Code: http.StatusNotFound, Message: "unknown service"})
+
+			return
+		}
+
+		if err := Encode(w, r, http.StatusOK, healthCheckResponse{Status: status}); err != nil {
+			s.OnErr(w, r, err)
+		}
+	}, metadata, serveWhileDraining())
+
+	s.Register(HealthService, "Live", func(w http.ResponseWriter, r *http.Request) {
+		_ = Encode(w, r, http.StatusOK, healthCheckResponse{Status: StatusServing})
+	}, metadata, serveWhileDraining())
+
+	s.Register(HealthService, "Ready", func(w http.ResponseWriter, r *http.Request) {
+		status, _ := health.Status("")
+		if s.drain.draining() {
+			status = StatusNotServing
+		}
+
+		code := http.StatusOK
+		if status != StatusServing {
+			code = http.StatusServiceUnavailable
+		}
+
+		_ = Encode(w, r, code, healthCheckResponse{Status: status})
+	}, metadata, serveWhileDraining())
+}
+
+func (s *server) hasService(service string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, rt := range s.routes {
+		if rt.service == service {
+			return true
+		}
+	}
+
+	return false
+}
+
+func serveWhileDraining() RouteOption {
+	return func(r *route) {
+		r.serveWhileDraining = true
+	}
+}
+
+// drainer counts in-flight calls and rejects new ones once draining started.
+type drainer struct {
+	mu       sync.Mutex
+	started  bool
+	inFlight int
+	idle     chan struct{}
+}
+
+func (d *drainer) enter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.started {
+		return false
+	}
+
+	d.inFlight++
+
+	return true
+}
+
+func (d *drainer) leave() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.inFlight--
+	if d.inFlight == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
+}
+
+func (d *drainer) start() {
+	d.mu.Lock()
+	d.started = true
+	d.mu.Unlock()
+}
+
+func (d *drainer) draining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.started
+}
+
+// wait blocks until there are no in-flight calls or the context is done.
+func (d *drainer) wait(ctx context.Context) error {
+	d.mu.Lock()
+	if d.inFlight == 0 {
+		d.mu.Unlock()
+
+		return nil
+	}
+
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+	}
+
+	idle := d.idle
+	d.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,121 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithHealth(t *testing.T) {
+	health := NewHealth()
+	srv := NewServer(WithHealth(health))
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {})
+	health.SetServingStatus("Degraded", StatusNotServing)
+
+	tests := []struct {
+		service string
+		status  int
+		body    string
+	}{
+		{service: "", status: http.StatusOK, body: `{"status":"SERVING"}`},
+		{service: "Service", status: http.StatusOK, body: `{"status":"SERVING"}`},
+		{service: "Degraded", status: http.StatusOK, body: `{"status":"NOT_SERVING"}`},
+		{service: "Unknown", status: http.StatusNotFound, body: `{"error":"unknown service"}`},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/gorpc.Health.Check", strings.NewReader(`{"service":"`+test.service+`"}`))
+		r.Header.Set("Content-Type", "application/json")
+		srv.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%q: expected %d status code, got %d", test.service, test.status, w.Code)
+		}
+
+		if w.Body.String() != test.body {
+			t.Errorf("%q: expected %q response body, got %q", test.service, test.body, w.Body.String())
+		}
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	srv := NewServer(WithHealth(NewHealth()))
+	started, release := make(chan struct{}), make(chan struct{})
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+
+	inFlight := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		srv.ServeHTTP(inFlight, httptest.NewRequest(http.MethodPost, "/Service.Method", nil))
+		close(served)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected shutdown to time out while call is in-flight, got %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/gorpc.Health.Ready", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected readiness check to fail with %d status code, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/Service.Method", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected %d status code, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	close(release)
+	<-served
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Error(err)
+	}
+
+	if inFlight.Code != http.StatusOK {
+		t.Errorf("expected in-flight call to complete with %d status code, got %d", http.StatusOK, inFlight.Code)
+	}
+}
+
+func TestDrainBatch(t *testing.T) {
+	srv := NewServer(WithBatch(1))
+	started, release := make(chan struct{}), make(chan struct{})
+	srv.Register("Service", "Wait", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+
+	w := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		body := `[{"service":"Service","method":"Wait"},{"service":"Service","method":"Method"}]`
+		r := httptest.NewRequest(http.MethodPost, "/gorpc.Batch", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		srv.ServeHTTP(w, r)
+		close(served)
+	}()
+	<-started
+
+	srv.Drain()
+	close(release)
+	<-served
+
+	expected := `[{"status":200,"body":{"status":"ok"}},{"status":200,"body":{"status":"ok"}}]`
+	if w.Body.String() != expected {
+		t.Errorf("expected in-flight batch to complete with %q, got %q", expected, w.Body.String())
+	}
+}
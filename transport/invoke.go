@@ -0,0 +1,151 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// InvokeOption configures in-process calls made with Server.Invoke.
+type InvokeOption func(*invocation)
+
+// WithoutCodec passes the request and response objects between the caller
+// and the method as is, skipping their JSON encoding. The objects are
+// shared with the method, not copied.
+func WithoutCodec() InvokeOption {
+	return func(inv *invocation) {
+		inv.skipCodec = true
+	}
+}
+
+// invocation is an in-process call. When the codec is skipped, Decode and
+// Encode of the called method exchange objects with it directly.
+type invocation struct {
+	path      string
+	request   interface{}
+	skipCodec bool
+	encoded   bool
+	status    int
+	payload   interface{}
+}
+
+type invocationContextKey struct{}
+
+// invocationFor returns the invocation skipping the codec if the request
+// is the one made by Server.Invoke and not a call nested into it.
+func invocationFor(r *http.Request) (*invocation, bool) {
+	inv, ok := r.Context().Value(invocationContextKey{}).(*invocation)
+	if !ok || !inv.skipCodec || inv.path != r.URL.Path {
+		return nil, false
+	}
+
+	return inv, true
+}
+
+func (s *server) Invoke(ctx context.Context, service, method string, request, response interface{}, options ...InvokeOption) error {
+	inv := &invocation{path: s.pathFn(service, method), request: request}
+	for i := range options {
+		options[i](inv)
+	}
+
+	body := []byte("{}")
+	if !inv.skipCodec {
+		var err error
+		if body, err = json.Marshal(request); err != nil {
+			return fmt.Errorf("%s.%s: marshal request: %w", service, method, err)
+		}
+	}
+
+	ctx = context.WithValue(ctx, dispatchContextKey{}, true)
+	ctx = context.WithValue(ctx, invocationContextKey{}, inv)
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, inv.path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s.%s: new request: %w", service, method, err)
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+
+	w := newResponseBuffer()
+	s.ServeHTTP(w, r)
+
+	if inv.encoded {
+		if inv.status != http.StatusOK {
+			return invocationError(inv.status, inv.payload)
+		}
+
+		if err := assign(response, inv.payload); err != nil {
+			return fmt.Errorf("%s.%s: %w", service, method, err)
+		}
+
+		return nil
+	}
+
+	if w.Status() != http.StatusOK {
+		return responseError(w.Status(), w.body.Bytes())
+	}
+
+	if err := json.Unmarshal(w.body.Bytes(), response); err != nil {
+		return fmt.Errorf("%s.%s: decode response: %w", service, method, err)
+	}
+
+	return nil
+}
+
+// invocationError returns the error payload encoded by the method
+// as ClientError.
+func invocationError(status int, payload interface{}) error {
+	if clientErr, ok := payload.(ClientError); ok {
+		clientErr.Code = status
+
+		return clientErr
+	}
+
+	body, _ := json.Marshal(payload)
+
+	return responseError(status, body)
+}
+
+// responseError decodes error response body into ClientError.
+func responseError(status int, body []byte) error {
+	clientErr := ClientError{Code: status}
+	_ = json.Unmarshal(body, &clientErr)
+	if clientErr.Message == "" {
+		clientErr.Message = http.StatusText(status)
+	}
+
+	return clientErr
+}
+
+// assign sets the value dst points to to src, which can be the value or
+// a pointer to it. Values of other types are converted with JSON.
+func assign(dst, src interface{}) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return fmt.Errorf("assign to %T: non-nil pointer expected", dst)
+	}
+
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr && !sv.IsNil() {
+		sv = sv.Elem()
+	}
+
+	if sv.IsValid() && sv.Type().AssignableTo(dv.Elem().Type()) {
+		dv.Elem().Set(sv)
+
+		return nil
+	}
+
+	body, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("marshal %T: %w", src, err)
+	}
+
+	if err := json.Unmarshal(body, dst); err != nil {
+		return fmt.Errorf("unmarshal %T into %T: %w", src, dst, err)
+	}
+
+	return nil
+}
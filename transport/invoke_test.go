@@ -0,0 +1,46 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestInvoke(t *testing.T) {
+	var calls int
+	srv := NewServer(WithMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			next.ServeHTTP(w, r)
+		})
+	}))
+	srv.Register("Service", "Greet", greetHandler(srv))
+
+	for _, options := range [][]InvokeOption{nil, {WithoutCodec()}} {
+		var response greetResponse
+		err := srv.Invoke(context.Background(), "Service", "Greet", greetRequest{Name: "Mat"}, &response, options...)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if response.Greeting != "Hello Mat" {
+			t.Errorf("expected %q greeting, got %q", "Hello Mat", response.Greeting)
+		}
+
+		err = srv.Invoke(context.Background(), "Service", "Greet", greetRequest{}, &response, options...)
+		expected := ClientError{Code: http.StatusBadRequest, Message: "name is required"}
+		if err != expected {
+			t.Errorf("expected %v error, got %v", expected, err)
+		}
+
+		err = srv.Invoke(context.Background(), "Service", "Unknown", greetRequest{}, &response, options...)
+		expected = ClientError{Code: http.StatusNotFound, Message: "not found"}
+		if err != expected {
+			t.Errorf("expected %v error, got %v", expected, err)
+		}
+	}
+
+	if calls != 4 {
+		t.Errorf("expected 4 calls through middleware, got %d", calls)
+	}
+}
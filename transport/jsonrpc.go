@@ -0,0 +1,221 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// JSONRPCService and JSONRPCMethod name the JSON-RPC 2.0 endpoint, by
+// default at POST /gorpc.JSONRPC. Methods are called by their
+// "Service.Method" names with params holding the request object.
+const (
+	JSONRPCService = "gorpc"
+	JSONRPCMethod  = "JSONRPC"
+)
+
+// JSON-RPC 2.0 error codes. Errors of methods other than bad requests
+// and internal errors are reported with JSONRPCServerError code and HTTP
+// status code of the error in the data.
+const (
+	JSONRPCParseError     = -32700
+	JSONRPCInvalidRequest = -32600
+	JSONRPCMethodNotFound = -32601
+	JSONRPCInvalidParams  = -32602
+	JSONRPCInternalError  = -32603
+	JSONRPCServerError    = -32000
+)
+
+type jsonrpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      jsonrpcID       `json:"id"`
+}
+
+type jsonrpcResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	Result  *json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpcError    `json:"error,omitempty"`
+	ID      jsonrpcID        `json:"id"`
+}
+
+// jsonrpcID is the id of the request kept as is. Requests without id are
+// notifications, while requests with null id must be answered.
+type jsonrpcID struct {
+	raw json.RawMessage
+	set bool
+}
+
+func (id *jsonrpcID) UnmarshalJSON(data []byte) error {
+	id.raw = append(id.raw[:0], data...)
+	id.set = true
+
+	return nil
+}
+
+func (id jsonrpcID) MarshalJSON() ([]byte, error) {
+	if !id.set {
+		return []byte("null"), nil
+	}
+
+	return id.raw, nil
+}
+
+type jsonrpcError struct {
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// registerJSONRPC registers the JSON-RPC 2.0 endpoint supporting batches
+// and notifications. Calls are authenticated on their own unless the
+// request to the endpoint is authenticated.
+func (s *server) registerJSONRPC() {
+	s.Register(JSONRPCService, JSONRPCMethod, func(w http.ResponseWriter, r *http.Request) {
+		body, err := readBody(r)
+		if err != nil {
+			s.OnErr(w, r, err)
+
+			return
+		}
+
+		body = bytes.TrimSpace(body)
+		if !json.Valid(body) {
+			_ = Encode(w, r, http.StatusOK, jsonrpcErrorResponse(jsonrpcID{}, JSONRPCParseError, "parse error"))
+
+			return
+		}
+
+		if body[0] != '[' {
+			response, ok := s.callJSONRPC(r, body)
+			if !ok {
+				w.WriteHeader(http.StatusNoContent)
+
+				return
+			}
+
+			_ = Encode(w, r, http.StatusOK, response)
+
+			return
+		}
+
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil || len(batch) == 0 {
+			_ = Encode(w, r, http.StatusOK, jsonrpcErrorResponse(jsonrpcID{}, JSONRPCInvalidRequest, "invalid request"))
+
+			return
+		}
+
+		responses := make([]jsonrpcResponse, 0, len(batch))
+		for i := range batch {
+			if response, ok := s.callJSONRPC(r, batch[i]); ok {
+				responses = append(responses, response)
+			}
+		}
+
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+
+		_ = Encode(w, r, http.StatusOK, responses)
+	}, WithMetadata(map[string]interface{}{"auth": "optional"}))
+}
+
+// callJSONRPC executes a single JSON-RPC call. It reports false for
+// notifications, which must not be answered.
+func (s *server) callJSONRPC(r *http.Request, body []byte) (jsonrpcResponse, bool) {
+	var request jsonrpcRequest
+	if err := json.Unmarshal(body, &request); err != nil || request.JSONRPC != "2.0" || request.Method == "" {
+		return jsonrpcErrorResponse(request.ID, JSONRPCInvalidRequest, "invalid request"), true
+	}
+
+	// routes of "gorpc" service, e.g. batches and the endpoint itself, can't be called
+	service, method := splitJSONRPCMethod(request.Method)
+	if !s.hasRoute(service, method) || service == JSONRPCService {
+		return jsonrpcErrorResponse(request.ID, JSONRPCMethodNotFound, "method not found"), request.ID.set
+	}
+
+	params, ok := jsonrpcParams(request.Params)
+	if !ok {
+		return jsonrpcErrorResponse(request.ID, JSONRPCInvalidParams, "invalid params"), request.ID.set
+	}
+
+	result := s.dispatch(r, service, method, params)
+	if !request.ID.set {
+		return jsonrpcResponse{}, false
+	}
+
+	if result.Status() == http.StatusOK {
+		// result is required on success, handlers without response body produce null
+		body := json.RawMessage("null")
+		if result.body.Len() > 0 {
+			body = result.body.Bytes()
+		}
+
+		return jsonrpcResponse{JSONRPC: "2.0", Result: &body, ID: request.ID}, true
+	}
+
+	var clientErr struct {
+		Error string `json:"error"`
+	}
+	_ = json.Unmarshal(result.body.Bytes(), &clientErr)
+	if clientErr.Error == "" {
+		clientErr.Error = http.StatusText(result.Status())
+	}
+
+	code := JSONRPCServerError
+	switch {
+	case result.Status() == http.StatusBadRequest:
+		code = JSONRPCInvalidParams
+	case result.Status() >= http.StatusInternalServerError:
+		code = JSONRPCInternalError
+	}
+
+	response := jsonrpcErrorResponse(request.ID, code, clientErr.Error)
+	response.Error.Data = map[string]interface{}{"status": result.Status()}
+
+	return response, true
+}
+
+// splitJSONRPCMethod splits "Service.Method" name at the last dot.
+func splitJSONRPCMethod(name string) (string, string) {
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return "", name
+	}
+
+	return name[:i], name[i+1:]
+}
+
+// jsonrpcParams returns the request object from params given by name
+// or as a single positional parameter.
+func jsonrpcParams(params json.RawMessage) ([]byte, bool) {
+	params = bytes.TrimSpace(params)
+	switch {
+	case len(params) == 0 || bytes.Equal(params, []byte("null")):
+		return []byte("{}"), true
+	case params[0] == '{':
+		return params, true
+	case params[0] == '[':
+		var positional []json.RawMessage
+		if err := json.Unmarshal(params, &positional); err != nil || len(positional) > 1 {
+			return nil, false
+		}
+
+		if len(positional) == 0 {
+			return []byte("{}"), true
+		}
+
+		return jsonrpcParams(positional[0])
+	default:
+		return nil, false
+	}
+}
+
+func jsonrpcErrorResponse(id jsonrpcID, code int, message string) jsonrpcResponse {
+	return jsonrpcResponse{JSONRPC: "2.0", Error: &jsonrpcError{Code: code, Message: message}, ID: id}
+}
@@ -0,0 +1,87 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithJSONRPC(t *testing.T) {
+	srv := NewServer(WithJSONRPC())
+	srv.Register("Service", "Greet", greetHandler(srv))
+	srv.Register("Service", "Ping", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name     string
+		body     string
+		status   int
+		expected string
+	}{
+		{
+			name:     "call",
+			body:     `{"jsonrpc":"2.0","method":"Service.Greet","params":{"name":"Mat"},"id":1}`,
+			status:   http.StatusOK,
+			expected: `{"jsonrpc":"2.0","result":{"greeting":"Hello Mat"},"id":1}`,
+		},
+		{
+			name:     "positional params",
+			body:     `{"jsonrpc":"2.0","method":"Service.Greet","params":[{"name":"Mat"}],"id":"a"}`,
+			status:   http.StatusOK,
+			expected: `{"jsonrpc":"2.0","result":{"greeting":"Hello Mat"},"id":"a"}`,
+		},
+		{
+			name:     "client error",
+			body:     `{"jsonrpc":"2.0","method":"Service.Greet","params":{},"id":1}`,
+			status:   http.StatusOK,
+			expected: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"name is required","data":{"status":400}},"id":1}`,
+		},
+		{
+			name:     "notification",
+			body:     `{"jsonrpc":"2.0","method":"Service.Greet","params":{"name":"Mat"}}`,
+			status:   http.StatusNoContent,
+			expected: ``,
+		},
+		{
+			name:     "null id",
+			body:     `{"jsonrpc":"2.0","method":"Service.Greet","params":{"name":"Mat"},"id":null}`,
+			status:   http.StatusOK,
+			expected: `{"jsonrpc":"2.0","result":{"greeting":"Hello Mat"},"id":null}`,
+		},
+		{
+			name:     "empty result",
+			body:     `{"jsonrpc":"2.0","method":"Service.Ping","id":1}`,
+			status:   http.StatusOK,
+			expected: `{"jsonrpc":"2.0","result":null,"id":1}`,
+		},
+		{
+			name:   "batch",
+			body:   `[{"jsonrpc":"2.0","method":"Service.Unknown","id":1},{"jsonrpc":"2.0","method":"Service.Greet","params":{"name":"Mat"}},{"foo":"bar"}]`,
+			status: http.StatusOK,
+			expected: `[{"jsonrpc":"2.0","error":{"code":-32601,"message":"method not found"},"id":1},` +
+				`{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":null}]`,
+		},
+		{
+			name:     "parse error",
+			body:     `{"jsonrpc":`,
+			status:   http.StatusOK,
+			expected: `{"jsonrpc":"2.0","error":{"code":-32700,"message":"parse error"},"id":null}`,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/gorpc.JSONRPC", strings.NewReader(test.body))
+		r.Header.Set("Content-Type", "application/json")
+		srv.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s: expected %d status code, got %d", test.name, test.status, w.Code)
+		}
+
+		if w.Body.String() != test.expected {
+			t.Errorf("%s: expected %q response body, got %q", test.name, test.expected, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package transport
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // register hash functions used by JWT algorithms
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JWTAuthenticator authenticates requests carrying JSON Web Token in the
+// Authorization header as a bearer token. Tokens must be signed with one of
+// RS256, RS384, RS512, ES256, ES384 or ES512 algorithms by a key from the
+// JSON Web Key Set file. The "sub" claim is used as principal ID.
+type JWTAuthenticator struct {
+	// Issuer, if not empty, must match the "iss" claim.
+	Issuer string
+	// Audience, if not empty, must be one of the "aud" claim values.
+	Audience string
+	// Leeway is the allowed clock skew when validating "exp" and "nbf" claims.
+	Leeway time.Duration
+
+	path string
+	mu   sync.RWMutex
+	keys []jsonWebKey
+}
+
+type jsonWebKey struct {
+	id  string
+	key crypto.PublicKey
+}
+
+// NewJWTAuthenticator makes JWTAuthenticator verifying tokens with keys
+// loaded from the JSON Web Key Set file.
+func NewJWTAuthenticator(jwksPath string) (*JWTAuthenticator, error) {
+	a := &JWTAuthenticator{path: jwksPath, Leeway: time.Minute}
+	if err := a.Reload(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Reload reads the JSON Web Key Set file again. Use it to rotate keys
+// without restarting the server.
+func (a *JWTAuthenticator) Reload() error {
+	content, err := os.ReadFile(a.path)
+	if err != nil {
+		return fmt.Errorf("read jwks: %w", err)
+	}
+
+	keys, err := parseJWKS(content)
+	if err != nil {
+		return fmt.Errorf("parse jwks %q: %w", a.path, err)
+	}
+
+	a.mu.Lock()
+	a.keys = keys
+	a.mu.Unlock()
+
+	return nil
+}
+
+func (a *JWTAuthenticator) Scheme() string { return "jwt" }
+
+func (a *JWTAuthenticator) credentialHeaders() []string { return []string{"Authorization"} }
+
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "Bearer ") {
+		return nil, nil
+	}
+
+	claims, err := a.verify(strings.TrimSpace(authorization[7:]), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims["sub"].(string)
+
+	return &Principal{ID: subject, Claims: claims}, nil
+}
+
+func (a *JWTAuthenticator) verify(token string, now time.Time) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrUnauthenticated
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	if !a.verifySignature(header.Alg, header.Kid, parts[0]+"."+parts[1], signature) {
+		return nil, ErrUnauthenticated
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	if exp, ok := claims["exp"].(float64); ok && now.After(time.Unix(int64(exp), 0).Add(a.Leeway)) {
+		return nil, ErrUnauthenticated
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(a.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, ErrUnauthenticated
+	}
+
+	if a.Issuer != "" && claims["iss"] != a.Issuer {
+		return nil, ErrUnauthenticated
+	}
+
+	if a.Audience != "" && !audienceContains(claims["aud"], a.Audience) {
+		return nil, ErrUnauthenticated
+	}
+
+	return claims, nil
+}
+
+func (a *JWTAuthenticator) verifySignature(alg, kid, signed string, signature []byte) bool {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return false
+	}
+
+	hasher := hash.New()
+	hasher.Write([]byte(signed))
+	digest := hasher.Sum(nil)
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for i := range a.keys {
+		if kid != "" && a.keys[i].id != kid {
+			continue
+		}
+
+		switch key := a.keys[i].key.(type) {
+		case *rsa.PublicKey:
+			if strings.HasPrefix(alg, "RS") && rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil {
+				return true
+			}
+		case *ecdsa.PublicKey:
+			size := (key.Curve.Params().BitSize + 7) / 8
+			if !strings.HasPrefix(alg, "ES") || len(signature) != 2*size {
+				continue
+			}
+
+			r, s := new(big.Int).SetBytes(signature[:size]), new(big.Int).SetBytes(signature[size:])
+			if ecdsa.Verify(key, digest, r, s) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func audienceContains(aud interface{}, audience string) bool {
+	switch value := aud.(type) {
+	case string:
+		return value == audience
+	case []interface{}:
+		for i := range value {
+			if value[i] == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	content, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(content, v)
+}
+
+func parseJWKS(content []byte) ([]jsonWebKey, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make([]jsonWebKey, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		switch k.Kty {
+		case "RSA":
+			n, err := decodeBigInt(k.N)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: n: %w", k.Kid, err)
+			}
+
+			e, err := decodeBigInt(k.E)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: e: %w", k.Kid, err)
+			}
+
+			keys = append(keys, jsonWebKey{id: k.Kid, key: &rsa.PublicKey{N: n, E: int(e.Int64())}})
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				return nil, fmt.Errorf("key %q: unsupported curve %q", k.Kid, k.Crv)
+			}
+
+			x, err := decodeBigInt(k.X)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: x: %w", k.Kid, err)
+			}
+
+			y, err := decodeBigInt(k.Y)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: y: %w", k.Kid, err)
+			}
+
+			keys = append(keys, jsonWebKey{id: k.Kid, key: &ecdsa.PublicKey{Curve: curve, X: x, Y: y}})
+		}
+	}
+
+	return keys, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	content, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(content), nil
+}
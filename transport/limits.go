@@ -0,0 +1,184 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultMaxBodySize = 1024 * 1024
+
+// requestLimits are stored in the request context for Decode.
+type requestLimits struct {
+	maxBodySize int64
+	maxDepth    int
+}
+
+type limitsContextKey struct{}
+
+func limitsFromContext(ctx context.Context) requestLimits {
+	limits, ok := ctx.Value(limitsContextKey{}).(requestLimits)
+	if !ok {
+		return requestLimits{maxBodySize: defaultMaxBodySize}
+	}
+
+	return limits
+}
+
+// limitsFor resolves limits of the route. Metadata keys "maxBodySize",
+// "maxDepth" and "timeout" override the server wide options.
+func (s *server) limitsFor(rt *route) (requestLimits, time.Duration) {
+	limits, timeout := requestLimits{maxBodySize: s.maxBodySize, maxDepth: s.maxDepth}, s.timeout
+
+	if size, ok := metadataNumber(rt.metadata, "maxBodySize"); ok {
+		limits.maxBodySize = int64(size)
+	}
+
+	if depth, ok := metadataNumber(rt.metadata, "maxDepth"); ok {
+		limits.maxDepth = int(depth)
+	}
+
+	if d, ok := metadataDuration(rt.metadata, "timeout"); ok {
+		timeout = d
+	}
+
+	return limits, timeout
+}
+
+// applyLimits stores body limits in the request context for Decode and
+// sets the deadline of the handler context if the route has a timeout.
+func (s *server) applyLimits(rt *route, next http.Handler) http.Handler {
+	limits, timeout := s.limitsFor(rt)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limits.maxBodySize > 0 && r.ContentLength > limits.maxBodySize {
+			s.OnErr(w, r, errBodyTooLarge)
+
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), limitsContextKey{}, limits)
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+var errBodyTooLarge = ClientError{
+	Code:    http.StatusRequestEntityTooLarge,
+	Message: "request body too large",
+}
+
+var errBodyCorrupted = ClientError{
+	Code:    http.StatusBadRequest,
+	Message: "invalid compressed request body",
+}
+
+var errBodyTooDeep = ClientError{
+	Code:    http.StatusBadRequest,
+	Message: "request body nested too deeply",
+}
+
+// readBody reads the request body within the limits from the request
+// context and replaces it with a copy, so it can be read again.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	limits := limitsFromContext(r.Context())
+
+	var reader io.Reader = r.Body
+	encoding := r.Header.Get("Content-Encoding")
+	compressed := encoding != "" && !strings.EqualFold(encoding, "identity")
+	if compressed {
+		compressor := compressionFromContext(r.Context()).lookup(encoding)
+		if compressor == nil {
+			return nil, ClientError{
+				Code:    http.StatusUnsupportedMediaType,
+				Message: "unsupported content encoding",
+			}
+		}
+
+		decompressed, err := compressor.NewReader(r.Body)
+		if err != nil {
+			return nil, errBodyCorrupted
+		}
+
+		defer decompressed.Close()
+
+		reader = decompressed
+	}
+
+	// limit is applied to decompressed body to protect from compression bombs
+	if limits.maxBodySize > 0 {
+		reader = io.LimitReader(reader, limits.maxBodySize+1)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		if compressed {
+			return nil, errBodyCorrupted
+		}
+
+		return nil, err
+	}
+
+	r.Header.Del("Content-Encoding")
+
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := checkLimits(body, limits); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func checkLimits(body []byte, limits requestLimits) error {
+	if limits.maxBodySize > 0 && int64(len(body)) > limits.maxBodySize {
+		return errBodyTooLarge
+	}
+
+	if limits.maxDepth > 0 && exceedsDepth(body, limits.maxDepth) {
+		return errBodyTooDeep
+	}
+
+	return nil
+}
+
+// exceedsDepth reports whether arrays and objects in JSON are nested
+// deeper than max levels.
+func exceedsDepth(body []byte, max int) bool {
+	var depth int
+	var inString, escaped bool
+
+	for _, c := range body {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+			if depth > max {
+				return true
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeHandler(srv Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request interface{}
+		if err := Decode(r, &request); err != nil {
+			srv.OnErr(w, r, err)
+			return
+		}
+
+		w.Write([]byte(`{"status":"ok"}`))
+	}
+}
+
+func TestWithMaxBodySize(t *testing.T) {
+	srv := NewServer(WithMaxBodySize(16))
+	srv.Register("Service", "Method", decodeHandler(srv))
+	srv.Register("Service", "Large", decodeHandler(srv), WithMetadata(map[string]interface{}{"maxBodySize": 64}))
+
+	tests := []struct {
+		path   string
+		body   string
+		status int
+	}{
+		{path: "/Service.Method", body: `{"name":"Mat"}`, status: http.StatusOK},
+		{path: "/Service.Method", body: `{"name":"Mat Ryer"}`, status: http.StatusRequestEntityTooLarge},
+		{path: "/Service.Large", body: `{"name":"Mat Ryer"}`, status: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+		r.Header.Set("Content-Type", "application/json")
+		// unknown content length must not bypass the limit
+		r.ContentLength = -1
+		srv.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s %s: expected %d status code, got %d", test.path, test.body, test.status, w.Code)
+		}
+	}
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	srv := NewServer(WithMaxDepth(2))
+	srv.Register("Service", "Method", decodeHandler(srv))
+
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{body: `{"names":["Mat","[[["]}`, status: http.StatusOK},
+		{body: `{"names":[["Mat"]]}`, status: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Service.Method", strings.NewReader(test.body))
+		r.Header.Set("Content-Type", "application/json")
+		srv.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s: expected %d status code, got %d", test.body, test.status, w.Code)
+		}
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	srv := NewServer(WithTimeout(time.Second))
+	var deadline time.Time
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		deadline, _ = r.Context().Deadline()
+	}, WithMetadata(map[string]interface{}{"timeout": "10ms"}))
+
+	start := time.Now()
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/Service.Method", nil))
+	if deadline.IsZero() || deadline.Sub(start) > 500*time.Millisecond {
+		t.Errorf("expected deadline within 10ms, got %s", deadline.Sub(start))
+	}
+}
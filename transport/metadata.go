@@ -0,0 +1,55 @@
+package transport
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// metadataNumber reads a numeric metadata value. Values parsed from the
+// definition are float64, while generated Go literals may hold ints.
+func metadataNumber(metadata map[string]interface{}, key string) (float64, bool) {
+	switch value := metadata[key].(type) {
+	case float64:
+		return value, true
+	case float32:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case json.Number:
+		f, err := value.Float64()
+
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func metadataObject(metadata map[string]interface{}, key string) (map[string]interface{}, bool) {
+	value, ok := metadata[key].(map[string]interface{})
+
+	return value, ok
+}
+
+func metadataString(metadata map[string]interface{}, key string) (string, bool) {
+	value, ok := metadata[key].(string)
+
+	return value, ok
+}
+
+// metadataDuration reads a duration given either as a Go duration
+// string ("1.5s") or as a number of seconds.
+func metadataDuration(metadata map[string]interface{}, key string) (time.Duration, bool) {
+	if value, ok := metadataString(metadata, key); ok {
+		d, err := time.ParseDuration(value)
+
+		return d, err == nil
+	}
+
+	if seconds, ok := metadataNumber(metadata, key); ok {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+
+	return 0, false
+}
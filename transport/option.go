@@ -1,7 +1,10 @@
 package transport
 
 import (
+	"log/slog"
+	"net/http"
 	"strings"
+	"time"
 )
 
 type Option func(*server)
@@ -12,6 +15,23 @@ func WithErrorHandler(handler ErrorHandler) Option {
 	}
 }
 
+// WithErrorRegistry sets the error handler to respond with client errors
+// mapped by the registry. Errors of Twirp calls are mapped with it as well.
+func WithErrorRegistry(registry *ErrorRegistry) Option {
+	return func(s *server) {
+		s.errRegistry = registry
+		s.errHandler = registry.ErrorHandler()
+	}
+}
+
+// WithErrorMode sets the status code of error responses: their own status
+// code (StatusCodeErrors) or 200 with the "error" field (EnvelopeErrors).
+func WithErrorMode(mode ErrorMode) Option {
+	return func(s *server) {
+		s.errorMode = mode
+	}
+}
+
 func WithPathPrefix(prefix string) Option {
 	trimmedPrefix := strings.Trim(prefix, "/")
 
@@ -27,3 +47,194 @@ func WithMiddleware(mw Middleware) Option {
 		s.mw = append(s.mw, mw)
 	}
 }
+
+// WithRateLimit limits the rate of calls to the method using a token bucket
+// per key (see WithRateLimitKey). Empty method applies the limit to every
+// method of the service, empty service and method apply it to all routes.
+// The most specific limit wins and takes precedence over "rateLimit" metadata.
+func WithRateLimit(service, method string, limit RateLimit) Option {
+	return func(s *server) {
+		s.rateLimits = append(s.rateLimits, rateLimitRule{service: service, method: method, limit: limit})
+	}
+}
+
+// WithRateLimitKey sets the function used to group requests into rate limit
+// buckets. Requests are limited by client IP before authentication by default.
+// Custom keys, e.g. KeyByPrincipal, are applied after authentication and
+// failed authentications are limited by client IP instead.
+func WithRateLimitKey(fn KeyFunc) Option {
+	return func(s *server) {
+		s.rateLimitKey = fn
+	}
+}
+
+// WithConcurrencyLimit limits the number of in-flight calls to the method.
+// Service and method are matched the same way as in WithRateLimit and
+// the limit takes precedence over "maxInFlight" metadata.
+func WithConcurrencyLimit(service, method string, max int) Option {
+	return func(s *server) {
+		s.concurrency = append(s.concurrency, concurrencyRule{service: service, method: method, max: max})
+	}
+}
+
+// WithAuthenticator adds authenticators that populate Principal in the
+// request context. When authenticators are configured, methods require
+// authentication unless their "auth" metadata is "optional" or "none".
+// The "auth" metadata can also list schemes allowed for the method.
+func WithAuthenticator(authenticators ...Authenticator) Option {
+	return func(s *server) {
+		s.authenticators = append(s.authenticators, authenticators...)
+	}
+}
+
+// WithMaxBodySize sets the maximum size of request bodies in bytes,
+// 1 MiB by default. Larger requests are rejected with 413 status code.
+// Zero disables the limit. Methods can override it with "maxBodySize" metadata.
+func WithMaxBodySize(size int64) Option {
+	return func(s *server) {
+		s.maxBodySize = size
+	}
+}
+
+// WithMaxDepth sets the maximum nesting depth of JSON request bodies.
+// Methods can override it with "maxDepth" metadata.
+func WithMaxDepth(depth int) Option {
+	return func(s *server) {
+		s.maxDepth = depth
+	}
+}
+
+// WithTimeout sets the deadline of the handler context. Methods can
+// override it with "timeout" metadata, e.g. "timeout: \"500ms\"".
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *server) {
+		s.timeout = timeout
+	}
+}
+
+// WithCompressors sets compressors used for responses and request bodies
+// in order of preference, replacing default gzip and deflate compressors.
+// Call it without arguments to disable compression.
+func WithCompressors(compressors ...Compressor) Option {
+	return func(s *server) {
+		s.compression = &compression{compressors: compressors, threshold: s.compression.threshold}
+	}
+}
+
+// WithCompressionThreshold sets the minimum size of response body
+// in bytes to be compressed, 1 KiB by default.
+func WithCompressionThreshold(size int) Option {
+	return func(s *server) {
+		s.compression = &compression{compressors: s.compression.compressors, threshold: size}
+	}
+}
+
+// WithNotFoundHandler replaces the handler of unknown paths, which
+// passes 404 ClientError to the error handler by default.
+func WithNotFoundHandler(handler http.Handler) Option {
+	return func(s *server) {
+		s.notFoundHandler = handler
+	}
+}
+
+// WithCORS enables Cross-Origin Resource Sharing, answering OPTIONS
+// preflight requests and setting CORS headers on responses. It panics if
+// credentials are allowed together with "*" origin, which would let any
+// website make authenticated calls.
+func WithCORS(options CORSOptions) Option {
+	if options.AllowCredentials && options.allowsAnyOrigin() {
+		panic("transport: CORS credentials can't be allowed for any origin")
+	}
+
+	return func(s *server) {
+		s.cors = &options
+	}
+}
+
+// WithReflection serves the JSON encoded definition, which generated
+// servers embed as GorpcDefinition, so clients can discover the services.
+func WithReflection(definition []byte) Option {
+	return func(s *server) {
+		s.reflection = definition
+	}
+}
+
+// WithExplorer serves the API explorer page generated with explorer.html.tmpl
+// template. It lets anyone call the methods, so enable it in development only.
+func WithExplorer(page []byte) Option {
+	return func(s *server) {
+		s.explorer = page
+	}
+}
+
+// WithHealth registers health checking routes reporting the status
+// held by Health. See HealthService for the list of routes.
+func WithHealth(health *Health) Option {
+	return func(s *server) {
+		s.health = health
+	}
+}
+
+// WithBatch enables the batch route executing multiple calls in a single
+// request with up to concurrency calls at once. See BatchService.
+func WithBatch(concurrency int) Option {
+	return func(s *server) {
+		s.batch = concurrency
+	}
+}
+
+// WithJSONRPC enables JSON-RPC 2.0 endpoint calling registered methods.
+// See JSONRPCService.
+func WithJSONRPC() Option {
+	return func(s *server) {
+		s.jsonrpc = true
+	}
+}
+
+// WithTwirp serves registered methods at Twirp compatible routes
+// /twirp/<package>.<Service>/<Method> and writes errors of these
+// routes in Twirp format. Empty package name serves /twirp/<Service>/<Method>.
+func WithTwirp(packageName string) Option {
+	return func(s *server) {
+		s.twirp = &twirp{packageName: packageName}
+	}
+}
+
+// RouteOption configures a single registered method.
+type RouteOption func(*route)
+
+// WithMetadata attaches definition metadata to the method. Generated
+// servers pass metadata extracted from the method comments.
+func WithMetadata(metadata map[string]interface{}) RouteOption {
+	return func(r *route) {
+		for key, value := range metadata {
+			r.metadata[key] = value
+		}
+	}
+}
+
+// WithRequestID assigns an ID to every request. The ID is taken from
+// X-Request-ID header when present or generated, stored in the context
+// (see RequestIDFromContext), echoed in the response and included
+// in error responses.
+func WithRequestID() Option {
+	return func(s *server) {
+		s.requestID = true
+	}
+}
+
+// WithAccessLog writes a record for every request to the logger with
+// service, method, status, duration and body sizes.
+func WithAccessLog(logger *slog.Logger) Option {
+	return func(s *server) {
+		s.accessLog = logger
+	}
+}
+
+// WithPanicReporter sets the function called with panics recovered
+// from methods, e.g. to report them with stack traces.
+func WithPanicReporter(reporter PanicReporter) Option {
+	return func(s *server) {
+		s.panicReporter = reporter
+	}
+}
@@ -0,0 +1,118 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicError is passed to the ErrorHandler when a method panics.
+// DefaultErrorHandler responds to it with 500 status code.
+type PanicError struct {
+	Service string
+	Method  string
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+func (e PanicError) Error() string {
+	return fmt.Sprintf("%s.%s: panic: %v", e.Service, e.Method, e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+
+	return err
+}
+
+// PanicReporter is called with the recovered panic before the error
+// response is written, e.g. to send it to a crash reporting service.
+type PanicReporter func(r *http.Request, err PanicError)
+
+// recoverPanics recovers panics of the method and its middleware and
+// passes them to the ErrorHandler as PanicError. http.ErrAbortHandler
+// is re-panicked to let net/http abort the response, which is also done
+// when the method panics after it has started writing the response.
+// Dispatched and invoked calls are not served by net/http, which could
+// recover the abort, so their buffered responses are replaced with the
+// error instead.
+func (s *server) recoverPanics(rt *route, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tracker := &writeTracker{ResponseWriter: w}
+
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+
+			dispatched := isDispatched(r.Context())
+			if err, ok := value.(error); ok && errors.Is(err, http.ErrAbortHandler) && !dispatched {
+				panic(value)
+			}
+
+			panicErr := PanicError{
+				Service: rt.service,
+				Method:  rt.method,
+				Value:   value,
+				Stack:   debug.Stack(),
+			}
+
+			if s.panicReporter != nil {
+				s.panicReporter(r, panicErr)
+			}
+
+			if tracker.written && (!dispatched || !resetResponseBuffer(w)) {
+				panic(http.ErrAbortHandler)
+			}
+
+			s.OnErr(w, r, panicErr)
+		}()
+
+		next.ServeHTTP(tracker, r)
+	})
+}
+
+// writeTracker reports whether the response has been started.
+type writeTracker struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (t *writeTracker) WriteHeader(status int) {
+	t.written = true
+	t.ResponseWriter.WriteHeader(status)
+}
+
+func (t *writeTracker) Write(p []byte) (int, error) {
+	t.written = true
+
+	return t.ResponseWriter.Write(p)
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer.
+func (t *writeTracker) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
+
+// resetResponseBuffer discards the response written to the responseBuffer
+// underlying the writer. It reports false if there is none.
+func resetResponseBuffer(w http.ResponseWriter) bool {
+	for {
+		switch writer := w.(type) {
+		case *responseBuffer:
+			writer.status = 0
+			writer.body.Reset()
+
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = writer.Unwrap()
+		default:
+			return false
+		}
+	}
+}
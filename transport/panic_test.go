@@ -0,0 +1,115 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	var reported PanicError
+	var handled error
+	srv := NewServer(
+		WithPanicReporter(func(r *http.Request, err PanicError) {
+			reported = err
+		}),
+		WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			handled = err
+			DefaultErrorHandler(w, r, err)
+		}),
+	)
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/Service.Method", strings.NewReader(`{}`))
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected %d status code, got %d", http.StatusInternalServerError, w.Code)
+	}
+
+	expected := `{"error":"internal server error"}`
+	if w.Body.String() != expected {
+		t.Errorf("expected %q response body, got %q", expected, w.Body.String())
+	}
+
+	if reported.Value != "boom" || len(reported.Stack) == 0 {
+		t.Errorf("expected reported panic with stack, got %v", reported)
+	}
+
+	if _, ok := handled.(PanicError); !ok {
+		t.Errorf("expected PanicError to be handled, got %T", handled)
+	}
+
+	expected = "Service.Method: panic: boom"
+	if handled.Error() != expected {
+		t.Errorf("expected %q error, got %q", expected, handled.Error())
+	}
+}
+
+func TestPanicAfterWrite(t *testing.T) {
+	srv := NewServer()
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		panic("boom")
+	})
+
+	defer func() {
+		if value := recover(); value != http.ErrAbortHandler {
+			t.Errorf("expected %v panic, got %v", http.ErrAbortHandler, value)
+		}
+	}()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/Service.Method", strings.NewReader(`{}`))
+	srv.ServeHTTP(w, r)
+}
+
+func TestPanicAfterWriteDispatched(t *testing.T) {
+	srv := NewServer(WithBatch(2))
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":`))
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/gorpc.Batch", strings.NewReader(`[{"service":"Service","method":"Method"}]`))
+	r.Header.Set("Content-Type", "application/json")
+	srv.ServeHTTP(w, r)
+
+	expected := `[{"status":500,"body":{"error":"internal server error"}}]`
+	if w.Body.String() != expected {
+		t.Errorf("expected %q response body, got %q", expected, w.Body.String())
+	}
+
+	for _, options := range [][]InvokeOption{nil, {WithoutCodec()}} {
+		var response struct{}
+		err := srv.Invoke(context.Background(), "Service", "Method", struct{}{}, &response, options...)
+		if clientErr, ok := err.(ClientError); !ok || clientErr.Code != http.StatusInternalServerError {
+			t.Errorf("expected %d ClientError, got %v", http.StatusInternalServerError, err)
+		}
+	}
+}
+
+func TestPanicInBatch(t *testing.T) {
+	srv := NewServer(WithBatch(2), WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		panic("error handler")
+	}))
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/gorpc.Batch", strings.NewReader(`[{"service":"Service","method":"Method"}]`))
+	r.Header.Set("Content-Type", "application/json")
+	srv.ServeHTTP(w, r)
+
+	expected := `[{"status":500,"body":{"error":"internal server error"}}]`
+	if w.Body.String() != expected {
+		t.Errorf("expected %q response body, got %q", expected, w.Body.String())
+	}
+}
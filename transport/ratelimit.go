@@ -0,0 +1,304 @@
+package transport
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit describes a token bucket. Rate is the number of tokens added
+// per second and Burst is the capacity of the bucket.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// KeyFunc returns the key requests are grouped by for rate limiting.
+type KeyFunc func(r *http.Request) string
+
+// KeyByIP groups requests by the remote address of the client.
+func KeyByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+type rateLimitRule struct {
+	service string
+	method  string
+	limit   RateLimit
+}
+
+type concurrencyRule struct {
+	service string
+	method  string
+	max     int
+}
+
+// ruleSpecificity returns how specifically a rule matches the route
+// or -1 if it does not match at all.
+func ruleSpecificity(service, method string, rt *route) int {
+	switch {
+	case service == "" && method == "":
+		return 0
+	case service == rt.service && method == "":
+		return 1
+	case service == rt.service && method == rt.method:
+		return 2
+	default:
+		return -1
+	}
+}
+
+func (s *server) rateLimitFor(rt *route) (RateLimit, bool) {
+	best, found := -1, RateLimit{}
+	for i := range s.rateLimits {
+		rule := s.rateLimits[i]
+		if specificity := ruleSpecificity(rule.service, rule.method, rt); specificity > best {
+			best, found = specificity, rule.limit
+		}
+	}
+
+	if best >= 0 {
+		return found, true
+	}
+
+	settings, ok := metadataObject(rt.metadata, "rateLimit")
+	if !ok {
+		return RateLimit{}, false
+	}
+
+	rate, ok := metadataNumber(settings, "rate")
+	if !ok {
+		return RateLimit{}, false
+	}
+
+	burst, ok := metadataNumber(settings, "burst")
+	if !ok {
+		burst = math.Max(1, rate)
+	}
+
+	return RateLimit{Rate: rate, Burst: int(burst)}, true
+}
+
+func (s *server) concurrencyFor(rt *route) (int, bool) {
+	best, found := -1, 0
+	for i := range s.concurrency {
+		rule := s.concurrency[i]
+		if specificity := ruleSpecificity(rule.service, rule.method, rt); specificity > best {
+			best, found = specificity, rule.max
+		}
+	}
+
+	if best >= 0 {
+		return found, true
+	}
+
+	max, ok := metadataNumber(rt.metadata, "maxInFlight")
+
+	return int(max), ok
+}
+
+// limit wraps the handler of the route with authentication and configured
+// rate and concurrency limits. Limits keyed by IP are applied before the
+// authentication, so that requests with bad credentials use up tokens too.
+// Custom keys may depend on the Principal and are applied after it, while
+// failed authentications are limited by IP. Rejected requests are passed
+// to the error handler as 429 ClientError with Retry-After header set.
+func (s *server) limit(rt *route, handler http.Handler) http.Handler {
+	limit, ok := s.rateLimitFor(rt)
+	rateLimited := ok && limit.Rate > 0
+
+	if rateLimited && s.rateLimitKey != nil {
+		handler = s.rateLimit(newTokenBuckets(limit), s.rateLimitKey, handler)
+	}
+
+	handler = s.authenticate(rt, handler)
+
+	if rateLimited && s.rateLimitKey != nil && len(s.authenticators) > 0 {
+		handler = s.limitAuthFailures(newTokenBuckets(limit), handler)
+	}
+
+	if rateLimited && s.rateLimitKey == nil {
+		handler = s.rateLimit(newTokenBuckets(limit), KeyByIP, handler)
+	}
+
+	if max, ok := s.concurrencyFor(rt); ok && max > 0 {
+		handler = s.concurrencyLimit(max, handler)
+	}
+
+	return handler
+}
+
+func (s *server) rateLimit(buckets *tokenBuckets, key KeyFunc, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, retryAfter := buckets.take(key(r), time.Now())
+		if !allowed {
+			s.rejectRateLimited(w, r, retryAfter)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+type authFailureContextKey struct{}
+
+// limitAuthFailures rejects requests from IPs that have used up their
+// tokens on failed authentications. Successful requests take no tokens.
+func (s *server) limitAuthFailures(buckets *tokenBuckets, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := KeyByIP(r)
+		if allowed, retryAfter := buckets.peek(key, time.Now()); !allowed {
+			s.rejectRateLimited(w, r, retryAfter)
+
+			return
+		}
+
+		var failed bool
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authFailureContextKey{}, &failed)))
+
+		if failed {
+			buckets.take(key, time.Now())
+		}
+	})
+}
+
+// reportAuthFailure marks the request for limitAuthFailures.
+func reportAuthFailure(r *http.Request) {
+	if failed, ok := r.Context().Value(authFailureContextKey{}).(*bool); ok {
+		*failed = true
+	}
+}
+
+func (s *server) rejectRateLimited(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	s.OnErr(w, r, ClientError{
+		Code:    http.StatusTooManyRequests,
+		Message: "rate limit exceeded",
+	})
+}
+
+func (s *server) concurrencyLimit(max int, next http.Handler) http.Handler {
+	slots := make(chan struct{}, max)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		default:
+			w.Header().Set("Retry-After", "1")
+			s.OnErr(w, r, ClientError{
+				Code:    http.StatusTooManyRequests,
+				Message: "too many concurrent requests",
+			})
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tokenBuckets holds a token bucket per key. Buckets that have been
+// refilled completely are forgotten periodically to bound memory usage.
+type tokenBuckets struct {
+	limit RateLimit
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBuckets(limit RateLimit) *tokenBuckets {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+
+	return &tokenBuckets{
+		limit:     limit,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// take consumes a token for the key. When the bucket is empty it returns
+// false and the time after which a token will be available.
+func (b *tokenBuckets) take(key string, now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bucket := b.bucket(key, now)
+	if bucket.tokens < 1 {
+		return false, b.retryAfter(bucket)
+	}
+
+	bucket.tokens--
+
+	return true, 0
+}
+
+// peek reports whether a token for the key is available without
+// consuming it.
+func (b *tokenBuckets) peek(key string, now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bucket := b.bucket(key, now)
+	if bucket.tokens < 1 {
+		return false, b.retryAfter(bucket)
+	}
+
+	return true, 0
+}
+
+// bucket returns the refilled bucket of the key.
+func (b *tokenBuckets) bucket(key string, now time.Time) *tokenBucket {
+	if now.Sub(b.lastSweep) > time.Minute {
+		b.sweep(now)
+	}
+
+	bucket, ok := b.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(b.limit.Burst), last: now}
+		b.buckets[key] = bucket
+	}
+
+	bucket.tokens = b.refill(bucket, now)
+	bucket.last = now
+
+	return bucket
+}
+
+func (b *tokenBuckets) retryAfter(bucket *tokenBucket) time.Duration {
+	return time.Duration((1 - bucket.tokens) / b.limit.Rate * float64(time.Second))
+}
+
+func (b *tokenBuckets) refill(bucket *tokenBucket, now time.Time) float64 {
+	tokens := bucket.tokens + now.Sub(bucket.last).Seconds()*b.limit.Rate
+
+	return math.Min(tokens, float64(b.limit.Burst))
+}
+
+func (b *tokenBuckets) sweep(now time.Time) {
+	for key, bucket := range b.buckets {
+		if b.refill(bucket, now) >= float64(b.limit.Burst) {
+			delete(b.buckets, key)
+		}
+	}
+
+	b.lastSweep = now
+}
@@ -0,0 +1,162 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	srv := NewServer(WithRateLimit("Service", "", RateLimit{Rate: 1, Burst: 2}))
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+
+	for i, expected := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Service.Method", nil)
+		srv.ServeHTTP(w, r)
+		if w.Code != expected {
+			t.Errorf("request %d: expected %d status code, got %d", i, expected, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/Service.Method", nil)
+	srv.ServeHTTP(w, r)
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("expected Retry-After to be %q, got %q", "1", w.Header().Get("Retry-After"))
+	}
+
+	expected := `{"error":"rate limit exceeded"}`
+	if w.Body.String() != expected {
+		t.Errorf("expected %q response body, got %q", expected, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/Service.Method", nil)
+	r.RemoteAddr = "192.0.2.2:1234"
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected %d status code for another client, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestRateLimitMetadata(t *testing.T) {
+	srv := NewServer()
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	}, WithMetadata(map[string]interface{}{"rateLimit": map[string]interface{}{"rate": 1, "burst": 1}}))
+
+	codes := make([]int, 0, 2)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Service.Method", nil)
+		srv.ServeHTTP(w, r)
+		codes = append(codes, w.Code)
+	}
+
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests {
+		t.Errorf("expected [200 429] status codes, got %v", codes)
+	}
+}
+
+func TestWithConcurrencyLimit(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	srv := NewServer(WithConcurrencyLimit("Service", "Method", 1))
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	go srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/Service.Method", nil))
+	<-started
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/Service.Method", nil))
+	close(release)
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected %d status code, got %d", http.StatusTooManyRequests, w.Code)
+	}
+}
+
+func TestTokenBucketsRefill(t *testing.T) {
+	buckets := newTokenBuckets(RateLimit{Rate: 2, Burst: 1})
+	now := time.Now()
+	if ok, _ := buckets.take("key", now); !ok {
+		t.Fatal("expected first token to be available")
+	}
+
+	ok, retryAfter := buckets.take("key", now)
+	if ok {
+		t.Fatal("expected bucket to be empty")
+	}
+
+	if retryAfter != 500*time.Millisecond {
+		t.Errorf("expected retry after %s, got %s", 500*time.Millisecond, retryAfter)
+	}
+
+	if ok, _ := buckets.take("key", now.Add(500*time.Millisecond)); !ok {
+		t.Error("expected bucket to be refilled")
+	}
+}
+
+func TestRateLimitAuthenticationFailures(t *testing.T) {
+	tests := []struct {
+		options  []Option
+		expected []int
+	}{
+		{
+			options:  nil,
+			expected: []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests},
+		},
+		{
+			options:  []Option{WithRateLimitKey(KeyByPrincipal)},
+			expected: []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests},
+		},
+	}
+
+	for _, test := range tests {
+		options := append(test.options,
+			WithAuthenticator(NewAPIKeyAuthenticator(map[string]string{"secret": "mat"})),
+			WithRateLimit("", "", RateLimit{Rate: 1, Burst: 2}),
+		)
+		srv := NewServer(options...)
+		srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"ok"}`))
+		})
+
+		for i, expected := range test.expected {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/Service.Method", nil)
+			r.Header.Set("X-API-KEY", "guess")
+			srv.ServeHTTP(w, r)
+			if w.Code != expected {
+				t.Errorf("request %d: expected %d status code, got %d", i, expected, w.Code)
+			}
+		}
+	}
+}
+
+func TestRateLimitByPrincipal(t *testing.T) {
+	srv := NewServer(
+		WithAuthenticator(NewAPIKeyAuthenticator(map[string]string{"mat": "mat", "david": "david"})),
+		WithRateLimit("", "", RateLimit{Rate: 1, Burst: 1}),
+		WithRateLimitKey(KeyByPrincipal),
+	)
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+
+	for i, key := range []string{"mat", "david"} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Service.Method", nil)
+		r.Header.Set("X-API-KEY", key)
+		srv.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("request %d: expected %d status code, got %d", i, http.StatusOK, w.Code)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ReflectionService and ReflectionMethod name the method serving the
+// definition of the server, by default at /gorpc.Reflection.Definition.
+// It accepts GET requests as well as POST.
+const (
+	ReflectionService = "gorpc.Reflection"
+	ReflectionMethod  = "Definition"
+)
+
+// registerReflection registers the method serving JSON encoded definition.
+func (s *server) registerReflection(definition []byte) {
+	s.Register(ReflectionService, ReflectionMethod, func(w http.ResponseWriter, r *http.Request) {
+		if err := Encode(w, r, http.StatusOK, json.RawMessage(definition)); err != nil {
+			s.OnErr(w, r, err)
+		}
+	}, WithMetadata(map[string]interface{}{"idempotent": true, "auth": "none"}))
+}
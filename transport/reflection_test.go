@@ -0,0 +1,26 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithReflection(t *testing.T) {
+	srv := NewServer(
+		WithReflection([]byte(`{"packageName": "main"}`)),
+		WithAuthenticator(NewAPIKeyAuthenticator(map[string]string{"key": "id"})),
+		WithPathPrefix("/gorpc/"),
+	)
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/gorpc/gorpc.Reflection.Definition", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected %d status code, got %d", http.StatusOK, w.Code)
+	}
+
+	expected := `{"packageName":"main"}`
+	if w.Body.String() != expected {
+		t.Errorf("expected %q response body, got %q", expected, w.Body.String())
+	}
+}
@@ -0,0 +1,72 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/damejeras/gorpc/transport/requestid"
+)
+
+// RequestIDHeader carries the request ID in requests and responses.
+const RequestIDHeader = requestid.Header
+
+// maxRequestIDLength limits the length of request IDs accepted from clients.
+const maxRequestIDLength = 128
+
+// RequestIDFromContext returns the request ID stored in the context by the
+// Server or ContextWithRequestID. It returns empty string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	return requestid.FromContext(ctx)
+}
+
+// ContextWithRequestID returns a copy of the context holding the request ID.
+// Generated clients forward it in X-Request-ID header.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return requestid.NewContext(ctx, id)
+}
+
+// assignRequestID stores the request ID in the context of the request and
+// echoes it in the response. The ID is taken from the context of in-memory
+// calls, X-Request-ID header if it is valid, or generated otherwise.
+func assignRequestID(w http.ResponseWriter, r *http.Request) *http.Request {
+	id := RequestIDFromContext(r.Context())
+	if id == "" {
+		id = r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		r = r.WithContext(ContextWithRequestID(r.Context(), id))
+	}
+
+	w.Header().Set(RequestIDHeader, id)
+
+	return r
+}
+
+// validRequestID accepts non-empty IDs of printable ASCII characters
+// without spaces to keep them safe for headers and logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		panic("transport: generate request ID: " + err.Error())
+	}
+
+	return hex.EncodeToString(id[:])
+}
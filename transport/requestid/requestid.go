@@ -0,0 +1,24 @@
+// Package requestid carries request IDs in contexts and HTTP headers. It has
+// no dependencies, so generated clients can forward request IDs without
+// importing transport.
+package requestid
+
+import "context"
+
+// Header carries the request ID in requests and responses.
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+// FromContext returns the request ID stored in the context. It returns empty
+// string if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+
+	return id
+}
+
+// NewContext returns a copy of the context holding the request ID.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
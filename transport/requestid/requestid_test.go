@@ -0,0 +1,17 @@
+package requestid
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewContext(t *testing.T) {
+	ctx := context.Background()
+	if id := FromContext(ctx); id != "" {
+		t.Errorf("expected empty request ID, got %q", id)
+	}
+
+	if id := FromContext(NewContext(ctx, "abc")); id != "abc" {
+		t.Errorf("expected %q request ID, got %q", "abc", id)
+	}
+}
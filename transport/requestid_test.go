@@ -0,0 +1,100 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithRequestID(t *testing.T) {
+	srv := NewServer(WithRequestID())
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		srv.OnErr(w, r, ClientError{Code: http.StatusNotFound, Message: "not found: " + RequestIDFromContext(r.Context())})
+	})
+
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{header: "abc-123", expected: "abc-123"},
+		{header: "", expected: ""},
+		{header: "with space", expected: ""},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Service.Method", strings.NewReader(`{}`))
+		if test.header != "" {
+			r.Header.Set(RequestIDHeader, test.header)
+		}
+
+		srv.ServeHTTP(w, r)
+		id := w.Header().Get(RequestIDHeader)
+		if test.expected != "" && id != test.expected {
+			t.Errorf("%q: expected %q request ID, got %q", test.header, test.expected, id)
+		}
+
+		if test.expected == "" && (id == "" || id == test.header) {
+			t.Errorf("%q: expected generated request ID, got %q", test.header, id)
+		}
+
+		expected := `{"error":"not found: ` + id + `","requestId":"` + id + `"}`
+		if w.Body.String() != expected {
+			t.Errorf("%q: expected %q response body, got %q", test.header, expected, w.Body.String())
+		}
+	}
+}
+
+func TestWithAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	srv := NewServer(WithRequestID(), WithAccessLog(slog.New(slog.NewJSONHandler(&buf, nil))))
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]string
+		_ = Decode(r, &request)
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/Service.Method", strings.NewReader(`{"name":"Mat"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(RequestIDHeader, "abc-123")
+	srv.ServeHTTP(w, r)
+
+	var record struct {
+		Level        string
+		Msg          string
+		Service      string
+		Method       string
+		Status       int
+		RequestSize  int64  `json:"request_size"`
+		ResponseSize int64  `json:"response_size"`
+		RequestID    string `json:"request_id"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("unmarshal access log %q: %v", buf.String(), err)
+	}
+
+	if record.Level != "INFO" || record.Msg != "rpc" {
+		t.Errorf("expected INFO rpc record, got %s %s", record.Level, record.Msg)
+	}
+
+	if record.Service != "Service" || record.Method != "Method" {
+		t.Errorf("expected Service.Method route, got %s.%s", record.Service, record.Method)
+	}
+
+	if record.Status != http.StatusOK {
+		t.Errorf("expected %d status, got %d", http.StatusOK, record.Status)
+	}
+
+	if record.RequestSize != 14 || record.ResponseSize != 15 {
+		t.Errorf("expected 14 and 15 bytes, got %d and %d", record.RequestSize, record.ResponseSize)
+	}
+
+	if record.RequestID != "abc-123" {
+		t.Errorf("expected %q request ID, got %q", "abc-123", record.RequestID)
+	}
+}
@@ -1,103 +1,398 @@
 package transport
 
 import (
-	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 type Server interface {
 	http.Handler
 
 	OnErr(w http.ResponseWriter, r *http.Request, err error)
-	Register(service, method string, h http.HandlerFunc)
+	Register(service, method string, h http.HandlerFunc, options ...RouteOption)
+	// Unregister removes the method. It reports false if the method was not registered.
+	Unregister(service, method string) bool
+	// Routes lists registered methods sorted by path.
+	Routes() []Route
+	// Drain makes the server reject new calls with 503 status code and
+	// fail readiness checks, while in-flight calls complete. Register it
+	// with http.Server.RegisterOnShutdown to drain on shutdown.
+	Drain()
+	// Shutdown drains the server and waits until in-flight calls
+	// complete or the context is done.
+	Shutdown(ctx context.Context) error
+	// Invoke calls the method in-process through the same middleware,
+	// limits and codec as HTTP calls and decodes its response into
+	// response. Error responses are returned as ClientError.
+	Invoke(ctx context.Context, service, method string, request, response interface{}, options ...InvokeOption) error
+}
+
+// Route describes a registered method.
+type Route struct {
+	Service  string
+	Method   string
+	Path     string
+	Metadata map[string]interface{}
 }
 
 type Middleware func(http.Handler) http.Handler
 
 type server struct {
-	routes          map[string]http.Handler
+	mu              sync.RWMutex
+	routes          map[string]*route
 	notFoundHandler http.Handler
 	errHandler      ErrorHandler
+	errRegistry     *ErrorRegistry
+	errorMode       ErrorMode
 	pathFn          func(service, method string) string
 	mw              []Middleware
+	rateLimits      []rateLimitRule
+	rateLimitKey    KeyFunc
+	concurrency     []concurrencyRule
+	authenticators  []Authenticator
+	maxBodySize     int64
+	maxDepth        int
+	timeout         time.Duration
+	compression     *compression
+	cors            *CORSOptions
+	reflection      []byte
+	explorer        []byte
+	health          *Health
+	drain           drainer
+	batch           int
+	jsonrpc         bool
+	twirp           *twirp
+	requestID       bool
+	accessLog       *slog.Logger
+	panicReporter   PanicReporter
+}
+
+type route struct {
+	service            string
+	method             string
+	metadata           map[string]interface{}
+	handler            http.Handler
+	serveWhileDraining bool
+	authHeaders        []string
+}
+
+// allow lists HTTP methods supported by the route for Allow header.
+func (rt *route) allow() string {
+	if rt.idempotent() {
+		return "GET, POST, OPTIONS"
+	}
+
+	return "POST, OPTIONS"
 }
 
 func NewServer(options ...Option) Server {
 	srv := &server{
-		routes:          make(map[string]http.Handler),
-		notFoundHandler: http.NotFoundHandler(),
-		errHandler:      DefaultErrorHandler,
+		routes:      make(map[string]*route),
+		errHandler:  DefaultErrorHandler,
+		errRegistry: NewErrorRegistry(),
 		pathFn: func(service, method string) string {
 			return "/" + service + "." + method
 		},
-		mw: make([]Middleware, 0),
+		mw:          make([]Middleware, 0),
+		maxBodySize: defaultMaxBodySize,
+		compression: defaultCompression,
 	}
 
+	srv.notFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.OnErr(w, r, ClientError{Code: http.StatusNotFound, Message: "not found"})
+	})
+
 	for i := range options {
 		options[i](srv)
 	}
 
+	if srv.reflection != nil {
+		srv.registerReflection(srv.reflection)
+	}
+
+	if srv.explorer != nil {
+		srv.registerExplorer(srv.explorer)
+	}
+
+	if srv.health != nil {
+		srv.registerHealth(srv.health)
+	}
+
+	if srv.batch > 0 {
+		srv.registerBatch(srv.batch)
+	}
+
+	if srv.jsonrpc {
+		srv.registerJSONRPC()
+	}
+
 	return srv
 }
 
 func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		s.notFoundHandler.ServeHTTP(w, r)
+	if s.requestID {
+		r = assignRequestID(w, r)
+	}
+
+	if s.accessLog != nil {
+		s.logAccess(w, r)
 
 		return
 	}
 
-	handler, ok := s.routes[r.URL.Path]
+	s.serve(w, r)
+}
+
+// serve handles the request and returns the matched route or nil
+// if the request did not match any.
+func (s *server) serve(w http.ResponseWriter, r *http.Request) *route {
+	r = r.WithContext(context.WithValue(r.Context(), compressionContextKey{}, s.compression))
+
+	if s.cors != nil && r.Method != http.MethodOptions {
+		s.cors.actual(w, r)
+	}
+
+	path := r.URL.Path
+	if s.twirp != nil && strings.HasPrefix(path, TwirpPrefix) {
+		r = r.WithContext(context.WithValue(r.Context(), twirpContextKey{}, true))
+
+		// preflight requests are answered below the same way as for other routes
+		service, method, ok := s.twirp.parse(path)
+		if !ok || (r.Method != http.MethodPost && r.Method != http.MethodOptions) || !s.hasRoute(service, method) {
+			s.OnErr(w, r, twirpBadRoute(r))
+
+			return nil
+		}
+
+		path = s.pathFn(service, method)
+	}
+
+	s.mu.RLock()
+	route, ok := s.routes[path]
+	s.mu.RUnlock()
+
 	if !ok {
 		s.notFoundHandler.ServeHTTP(w, r)
 
-		return
+		return nil
+	}
+
+	allow := route.allow()
+	if isTwirp(r.Context()) {
+		allow = "POST, OPTIONS"
+	}
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", allow)
+		if s.cors != nil {
+			s.cors.preflight(w, r, allow)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+		return route
+	}
+
+	if r.Method != http.MethodPost && (r.Method != http.MethodGet || !route.idempotent()) {
+		w.Header().Set("Allow", allow)
+		s.OnErr(w, r, ClientError{Code: http.StatusMethodNotAllowed, Message: "method not allowed"})
+
+		return route
 	}
 
-	handler.ServeHTTP(w, r)
+	// dispatched calls run within the parent request holding a slot already
+	if !route.serveWhileDraining && !isDispatched(r.Context()) {
+		if !s.drain.enter() {
+			w.Header().Set("Connection", "close")
+			s.OnErr(w, r, ClientError{Code: http.StatusServiceUnavailable, Message: "server is shutting down"})
+
+			return route
+		}
+
+		defer s.drain.leave()
+	}
+
+	route.handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeContextKey{}, route)))
+
+	return route
+}
+
+func (s *server) Drain() {
+	s.drain.start()
+	if s.health != nil {
+		s.health.Shutdown()
+	}
+}
+
+func (s *server) Shutdown(ctx context.Context) error {
+	s.Drain()
+
+	return s.drain.wait(ctx)
 }
 
 func (s *server) OnErr(w http.ResponseWriter, r *http.Request, err error) {
+	if isTwirp(r.Context()) {
+		writeTwirpError(w, r, s.errRegistry, err)
+
+		return
+	}
+
+	if s.errorMode == EnvelopeErrors && !isDispatched(r.Context()) {
+		w = envelopeWriter{ResponseWriter: w}
+	}
+
 	s.errHandler(w, r, err)
 }
 
-func (s *server) Register(service, method string, handler http.HandlerFunc) {
+func (s *server) Register(service, method string, handler http.HandlerFunc, options ...RouteOption) {
+	rt := &route{
+		service:  service,
+		method:   method,
+		metadata: make(map[string]interface{}),
+	}
+
+	for i := range options {
+		options[i](rt)
+	}
+
+	rt.handler = s.applyLimits(rt, s.limit(rt, handler))
 	if len(s.mw) > 0 {
-		s.routes[s.pathFn(service, method)] = chainMiddleware(handler, s.mw...)
-	} else {
-		s.routes[s.pathFn(service, method)] = handler
+		rt.handler = chainMiddleware(rt.handler, s.mw...)
 	}
+
+	rt.handler = s.recoverPanics(rt, rt.handler)
+
+	s.mu.Lock()
+	s.routes[s.pathFn(service, method)] = rt
+	s.mu.Unlock()
+}
+
+func (s *server) hasRoute(service, method string) bool {
+	s.mu.RLock()
+	_, ok := s.routes[s.pathFn(service, method)]
+	s.mu.RUnlock()
+
+	return ok
+}
+
+func (s *server) Unregister(service, method string) bool {
+	path := s.pathFn(service, method)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.routes[path]; !ok {
+		return false
+	}
+
+	delete(s.routes, path)
+
+	return true
+}
+
+func (s *server) Routes() []Route {
+	s.mu.RLock()
+	routes := make([]Route, 0, len(s.routes))
+	for path, rt := range s.routes {
+		metadata := make(map[string]interface{}, len(rt.metadata))
+		for key, value := range rt.metadata {
+			metadata[key] = value
+		}
+
+		routes = append(routes, Route{
+			Service:  rt.service,
+			Method:   rt.method,
+			Path:     path,
+			Metadata: metadata,
+		})
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Path < routes[j].Path
+	})
+
+	return routes
 }
 
 func Encode(w http.ResponseWriter, r *http.Request, status int, payload interface{}) error {
+	if inv, ok := invocationFor(r); ok {
+		inv.encoded, inv.status, inv.payload = true, status, payload
+		w.WriteHeader(status)
+
+		return nil
+	}
+
 	bodyBytes, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshal payload: %w", err)
 	}
 
-	var out io.Writer = w
-	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-		w.Header().Set("Content-Encoding", "gzip")
-		gzw := gzip.NewWriter(w)
-		out = gzw
-		defer gzw.Close()
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if !cacheable(w, r, status, bodyBytes) {
+		return nil
+	}
+
+	var compressor Compressor
+	if c := compressionFromContext(r.Context()); len(c.compressors) > 0 {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if len(bodyBytes) >= c.threshold {
+			compressor = c.negotiate(r.Header.Get("Accept-Encoding"))
+		}
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if compressor == nil {
+		w.WriteHeader(status)
+		if _, err := w.Write(bodyBytes); err != nil {
+			return fmt.Errorf("write body: %w", err)
+		}
+
+		return nil
+	}
+
+	w.Header().Set("Content-Encoding", compressor.Encoding())
 	w.WriteHeader(status)
 
+	out := compressor.NewWriter(w)
 	if _, err := out.Write(bodyBytes); err != nil {
+		_ = out.Close()
+
 		return fmt.Errorf("write body: %w", err)
 	}
 
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("close %s writer: %w", compressor.Encoding(), err)
+	}
+
 	return nil
 }
 
 func Decode(r *http.Request, v interface{}) error {
+	if inv, ok := invocationFor(r); ok {
+		if err := assign(v, inv.request); err != nil {
+			return DecodeError{Err: err}
+		}
+
+		return nil
+	}
+
+	if r.Method == http.MethodGet {
+		body, err := queryBody(r)
+		if err != nil {
+			return err
+		}
+
+		return decodeBody(body, v)
+	}
+
 	if r.Header.Get("Content-type") != "application/json" {
 		return ClientError{
 			Code:    http.StatusUnsupportedMediaType,
@@ -105,11 +400,20 @@ func Decode(r *http.Request, v interface{}) error {
 		}
 	}
 
-	if err := json.NewDecoder(io.LimitReader(r.Body, 1024*1024)).Decode(v); err != nil {
-		return fmt.Errorf("decode request body: %w", err)
+	body, err := readBody(r)
+	if err != nil {
+		return err
 	}
 
-	return r.Body.Close()
+	return decodeBody(body, v)
+}
+
+func decodeBody(body []byte, v interface{}) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return DecodeError{Err: err}
+	}
+
+	return nil
 }
 
 func chainMiddleware(handle http.Handler, mw ...Middleware) http.Handler {
@@ -110,3 +110,85 @@ func TestDecode(t *testing.T) {
 		t.Errorf("first request object's name had to be Aaron")
 	}
 }
+
+func TestRoutes(t *testing.T) {
+	srv := NewServer()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	srv.Register("Service", "Method", handler, WithMetadata(map[string]interface{}{"featured": true}))
+	srv.Register("Another", "Method", handler)
+
+	routes := srv.Routes()
+	if len(routes) != 2 {
+		t.Fatalf("expected %d routes, got %d", 2, len(routes))
+	}
+
+	if routes[0].Path != "/Another.Method" || routes[1].Path != "/Service.Method" {
+		t.Errorf("expected routes to be sorted by path, got %q and %q", routes[0].Path, routes[1].Path)
+	}
+
+	if routes[1].Service != "Service" || routes[1].Method != "Method" || routes[1].Metadata["featured"] != true {
+		t.Errorf("unexpected route %+v", routes[1])
+	}
+
+	if !srv.Unregister("Service", "Method") {
+		t.Error("expected Service.Method to be unregistered")
+	}
+
+	if srv.Unregister("Service", "Method") {
+		t.Error("expected Service.Method to be unregistered only once")
+	}
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/Service.Method", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected %d status code, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestConcurrentRegister(t *testing.T) {
+	srv := NewServer()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {})
+			srv.Unregister("Service", "Method")
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/Service.Method", nil))
+		srv.Routes()
+	}
+
+	<-done
+}
+
+func TestWithErrorMode(t *testing.T) {
+	srv := NewServer(WithErrorMode(EnvelopeErrors), WithBatch(1))
+	srv.Register("Service", "Method", func(w http.ResponseWriter, r *http.Request) {
+		srv.OnErr(w, r, ClientError{Code: http.StatusConflict, Message: "conflict"})
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/Service.Method", strings.NewReader(`{}`))
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected %d status code, got %d", http.StatusOK, w.Code)
+	}
+
+	expected := `{"error":"conflict"}`
+	if w.Body.String() != expected {
+		t.Errorf("expected %q response body, got %q", expected, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/gorpc.Batch", strings.NewReader(`[{"service":"Service","method":"Method","body":{}}]`))
+	r.Header.Set("Content-Type", "application/json")
+	srv.ServeHTTP(w, r)
+
+	expected = `[{"status":409,"body":{"error":"conflict"}}]`
+	if w.Body.String() != expected {
+		t.Errorf("expected %q batch response body, got %q", expected, w.Body.String())
+	}
+}
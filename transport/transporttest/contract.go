@@ -0,0 +1,118 @@
+package transporttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"testing"
+)
+
+// Interaction is a recorded call of a method.
+type Interaction struct {
+	Service  string          `json:"service"`
+	Method   string          `json:"method"`
+	Request  json.RawMessage `json:"request"`
+	Status   int             `json:"status"`
+	Response json.RawMessage `json:"response"`
+}
+
+// ReadInteractions reads the JSON list of interactions from the file.
+// It fails the test if the file can not be read.
+func ReadInteractions(tb testing.TB, path string) []Interaction {
+	tb.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("read interactions: %v", err)
+	}
+
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		tb.Fatalf("decode interactions %s: %v", path, err)
+	}
+
+	return interactions
+}
+
+// MatchShape returns an error if the actual JSON value does not have the
+// shape of the example: it has fields the example does not have or values
+// of other JSON types. Fields missing from the actual value and null values
+// match. Array elements are matched against the first element of the example
+// array and empty example objects match any object.
+func MatchShape(example, actual []byte) error {
+	var exampleValue, actualValue interface{}
+	if err := json.Unmarshal(example, &exampleValue); err != nil {
+		return fmt.Errorf("decode example: %w", err)
+	}
+
+	if err := json.Unmarshal(actual, &actualValue); err != nil {
+		return fmt.Errorf("decode value: %w", err)
+	}
+
+	return matchShape("$", exampleValue, actualValue)
+}
+
+func matchShape(path string, example, actual interface{}) error {
+	if example == nil || actual == nil {
+		return nil
+	}
+
+	if jsonType(example) != jsonType(actual) {
+		return fmt.Errorf("%s: expected %s, got %s", path, jsonType(example), jsonType(actual))
+	}
+
+	switch example := example.(type) {
+	case map[string]interface{}:
+		if len(example) == 0 {
+			return nil
+		}
+
+		actual := actual.(map[string]interface{})
+		keys := make([]string, 0, len(actual))
+		for key := range actual {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := example[key]
+			if !ok {
+				return fmt.Errorf("%s.%s: unexpected field", path, key)
+			}
+
+			if err := matchShape(path+"."+key, value, actual[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if len(example) == 0 {
+			return nil
+		}
+
+		for i, value := range actual.([]interface{}) {
+			if err := matchShape(fmt.Sprintf("%s[%d]", path, i), example[0], value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+
+	return "null"
+}
@@ -0,0 +1,31 @@
+package transporttest
+
+import (
+	"testing"
+)
+
+func TestMatchShape(t *testing.T) {
+	example := `{"greeting":{"text":"Hello"},"names":["Mat"],"count":1,"error":"something went wrong"}`
+	tests := []struct {
+		actual   string
+		expected string
+	}{
+		{actual: `{"greeting":{"text":"Hi"},"names":["David","Mat"],"count":2}`},
+		{actual: `{"greeting":null,"names":null}`},
+		{actual: `{"greeting":{"text":1}}`, expected: "$.greeting.text: expected string, got number"},
+		{actual: `{"names":["Mat",false]}`, expected: "$.names[1]: expected string, got boolean"},
+		{actual: `{"greeting":{},"name":"Mat"}`, expected: "$.name: unexpected field"},
+		{actual: `[]`, expected: "$: expected object, got array"},
+	}
+
+	for _, test := range tests {
+		err := MatchShape([]byte(example), []byte(test.actual))
+		if test.expected == "" && err != nil {
+			t.Errorf("%s: expected no error, got %v", test.actual, err)
+		}
+
+		if test.expected != "" && (err == nil || err.Error() != test.expected) {
+			t.Errorf("%s: expected %q error, got %v", test.actual, test.expected, err)
+		}
+	}
+}
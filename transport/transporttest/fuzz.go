@@ -0,0 +1,72 @@
+package transporttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/damejeras/gorpc/transport"
+)
+
+type fuzzPanicContextKey struct{}
+
+// Fuzz fuzzes the method with request bodies mutated from the seed corpus
+// added with f.Add. The server is created with the options, overriding the
+// PanicReporter, and register registers the service on it. The fuzz test
+// fails if the method panics, responds with invalid JSON or with a status
+// code other than 200 or 4xx.
+func Fuzz(f *testing.F, service, method string, register func(server transport.Server), options ...transport.Option) {
+	f.Helper()
+
+	options = append(options, transport.WithPanicReporter(reportFuzzPanic))
+	server := transport.NewServer(options...)
+	register(server)
+
+	path, ok := routePath(server, service, method)
+	if !ok {
+		f.Fatalf("%s.%s is not registered", service, method)
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		if err := fuzzCall(server, path, body); err != nil {
+			t.Errorf("%s.%s: %v\nrequest: %q", service, method, err, body)
+		}
+	})
+}
+
+// fuzzCall calls the method with the body and returns an error if it
+// panics or its response is not allowed.
+func fuzzCall(server transport.Server, path string, body []byte) error {
+	var panicErr *transport.PanicError
+	ctx := context.WithValue(context.Background(), fuzzPanicContextKey{}, &panicErr)
+
+	r := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body)).WithContext(ctx)
+	r.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+
+	if panicErr != nil {
+		return fmt.Errorf("panic: %v\n%s", panicErr.Value, panicErr.Stack)
+	}
+
+	if w.Code != http.StatusOK && (w.Code < 400 || w.Code > 499) {
+		return fmt.Errorf("unexpected %d status code: %s", w.Code, w.Body.String())
+	}
+
+	if !json.Valid(w.Body.Bytes()) {
+		return fmt.Errorf("invalid JSON response: %q", w.Body.String())
+	}
+
+	return nil
+}
+
+func reportFuzzPanic(r *http.Request, err transport.PanicError) {
+	if panicErr, ok := r.Context().Value(fuzzPanicContextKey{}).(**transport.PanicError); ok {
+		*panicErr = &err
+	}
+}
@@ -0,0 +1,42 @@
+package transporttest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/damejeras/gorpc/transport"
+)
+
+func FuzzGreet(f *testing.F) {
+	f.Add([]byte(`{"name":"Mat"}`))
+	f.Add([]byte(`{"name":1}`))
+	f.Add([]byte(`[`))
+
+	Fuzz(f, "Service", "Greet", registerGreet)
+}
+
+func TestFuzzCall(t *testing.T) {
+	server := transport.NewServer(transport.WithPanicReporter(reportFuzzPanic))
+	registerGreet(server)
+
+	tests := []struct {
+		body string
+		err  string
+	}{
+		{body: `{"name":"Mat"}`},
+		{body: `{`},
+		{body: `{"name":"panic"}`, err: "panic: unexpected name"},
+		{body: `{"name":"text"}`, err: `invalid JSON response: "Hello"`},
+	}
+
+	for _, test := range tests {
+		err := fuzzCall(server, "/Service.Greet", []byte(test.body))
+		if test.err == "" && err != nil {
+			t.Errorf("%s: expected no error, got %v", test.body, err)
+		}
+
+		if test.err != "" && (err == nil || !strings.HasPrefix(err.Error(), test.err)) {
+			t.Errorf("%s: expected %q error, got %v", test.body, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package transporttest
+
+import (
+	"net/http"
+
+	"github.com/damejeras/gorpc/transport"
+)
+
+// registerGreet registers the Service.Greet method shared by the tests. Names
+// "panic" and "text" make it panic and write invalid JSON for fuzz tests.
+func registerGreet(server transport.Server) {
+	server.Register("Service", "Greet", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Name string `json:"name"`
+		}
+		if err := transport.Decode(r, &request); err != nil {
+			server.OnErr(w, r, err)
+			return
+		}
+
+		if request.Name == "panic" {
+			panic("unexpected name")
+		}
+
+		if request.Name == "text" {
+			_, _ = w.Write([]byte("Hello"))
+			return
+		}
+
+		_ = transport.Encode(w, r, http.StatusOK, map[string]string{"greeting": "Hello " + request.Name})
+	})
+}
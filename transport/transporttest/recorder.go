@@ -0,0 +1,243 @@
+package transporttest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Mode selects whether Recorder records or replays interactions.
+type Mode int
+
+const (
+	// ModeReplay responds with the recorded interactions without making requests.
+	ModeReplay Mode = iota
+	// ModeRecord makes requests and writes the interactions to the file
+	// when the test completes.
+	ModeRecord
+)
+
+// Recorder is http.RoundTripper recording calls of methods to a file and
+// replaying them. Recorded calls are matched by service, method and request
+// body. Use it as Transport of HTTPClient of the generated Go client:
+//
+//	client.HTTPClient = transporttest.NewRecorder(t, "testdata/greeter.json", transporttest.ModeReplay).Client()
+type Recorder struct {
+	// Transport makes requests when recording. It defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+
+	tb           testing.TB
+	path         string
+	mode         Mode
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+// NewRecorder returns Recorder in the mode. Replaying reads interactions
+// from the file and fails the test if it can not be read, recording writes
+// them to the file once the test and its subtests complete.
+func NewRecorder(tb testing.TB, path string, mode Mode) *Recorder {
+	tb.Helper()
+
+	rec := &Recorder{tb: tb, path: path, mode: mode}
+	if mode == ModeReplay {
+		rec.interactions = ReadInteractions(tb, path)
+		rec.replayed = make([]bool, len(rec.interactions))
+
+		return rec
+	}
+
+	tb.Cleanup(rec.save)
+
+	return rec
+}
+
+// Client returns http.Client using the Recorder as its transport.
+func (rec *Recorder) Client() *http.Client {
+	return &http.Client{Transport: rec}
+}
+
+func (rec *Recorder) RoundTrip(r *http.Request) (*http.Response, error) {
+	service, method := splitPath(r.URL.Path)
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return nil, fmt.Errorf("transporttest: read request body: %w", err)
+		}
+
+		_ = r.Body.Close()
+	}
+
+	if rec.mode == ModeReplay {
+		return rec.replay(r, service, method, body)
+	}
+
+	return rec.record(r, service, method, body)
+}
+
+func (rec *Recorder) replay(r *http.Request, service, method string, body []byte) (*http.Response, error) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	match := -1
+	for i := range rec.interactions {
+		interaction := rec.interactions[i]
+		if interaction.Service != service || interaction.Method != method || !jsonEqual(interaction.Request, body) {
+			continue
+		}
+
+		// identical calls are replayed in order, the last one repeatedly
+		match = i
+		if !rec.replayed[i] {
+			break
+		}
+	}
+
+	if match < 0 {
+		return nil, fmt.Errorf("transporttest: no recorded %s.%s call with request %s", service, method, body)
+	}
+
+	rec.replayed[match] = true
+	interaction := rec.interactions[match]
+
+	// the file keeps bodies indented
+	var response bytes.Buffer
+	if err := json.Compact(&response, interaction.Response); err != nil {
+		return nil, fmt.Errorf("transporttest: compact recorded response: %w", err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json; charset=utf-8"}},
+		Body:          io.NopCloser(&response),
+		ContentLength: int64(response.Len()),
+		Request:       r,
+	}, nil
+}
+
+func (rec *Recorder) record(r *http.Request, service, method string, body []byte) (*http.Response, error) {
+	transport := rec.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	r = r.Clone(r.Context())
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+
+	resp, err := transport.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// responses are recorded and returned decompressed
+	var reader io.Reader = resp.Body
+	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
+		gzipReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			_ = resp.Body.Close()
+
+			return nil, fmt.Errorf("transporttest: new gzip reader: %w", err)
+		}
+
+		reader = gzipReader
+	}
+
+	respBody, err := io.ReadAll(reader)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("transporttest: read response body: %w", err)
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	resp.ContentLength = int64(len(respBody))
+	resp.Uncompressed = true
+
+	rec.mu.Lock()
+	rec.interactions = append(rec.interactions, Interaction{
+		Service:  service,
+		Method:   method,
+		Request:  rawJSON(body),
+		Status:   resp.StatusCode,
+		Response: rawJSON(respBody),
+	})
+	rec.mu.Unlock()
+
+	return resp, nil
+}
+
+func (rec *Recorder) save() {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	data, err := json.MarshalIndent(rec.interactions, "", "\t")
+	if err != nil {
+		rec.tb.Errorf("encode interactions: %v", err)
+
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(rec.path), 0o755); err != nil {
+		rec.tb.Errorf("write interactions: %v", err)
+
+		return
+	}
+
+	if err := os.WriteFile(rec.path, append(data, '\n'), 0o644); err != nil {
+		rec.tb.Errorf("write interactions: %v", err)
+	}
+}
+
+// splitPath returns service and method of the "/prefix/Service.Method" path.
+func splitPath(path string) (string, string) {
+	name := path[strings.LastIndex(path, "/")+1:]
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 {
+		return "", name
+	}
+
+	return name[:dot], name[dot+1:]
+}
+
+// rawJSON keeps valid JSON as is and encodes anything else as JSON string.
+func rawJSON(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return json.RawMessage("null")
+	}
+
+	if json.Valid(body) {
+		return body
+	}
+
+	encoded, _ := json.Marshal(string(body))
+
+	return encoded
+}
+
+// jsonEqual compares the recorded and the actual body as JSON values.
+func jsonEqual(recorded json.RawMessage, body []byte) bool {
+	var recordedValue, actualValue interface{}
+	if json.Unmarshal(recorded, &recordedValue) != nil || json.Unmarshal(rawJSON(body), &actualValue) != nil {
+		return bytes.Equal(recorded, body)
+	}
+
+	return reflect.DeepEqual(recordedValue, actualValue)
+}
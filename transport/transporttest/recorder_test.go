@@ -0,0 +1,71 @@
+package transporttest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/damejeras/gorpc/transport"
+)
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+
+	srv := transport.NewServer(transport.WithCompressionThreshold(0))
+	registerGreet(srv)
+
+	httpServer := httptest.NewServer(srv)
+	defer httpServer.Close()
+
+	path := filepath.Join(dir, "greeter.json")
+	t.Run("record", func(t *testing.T) {
+		client := NewRecorder(t, path, ModeRecord).Client()
+		expectGreeting(t, client, httpServer.URL, `{"name":"Mat"}`, `{"greeting":"Hello Mat"}`)
+	})
+
+	httpServer.Close()
+
+	client := NewRecorder(t, path, ModeReplay).Client()
+	expectGreeting(t, client, httpServer.URL, `{ "name": "Mat" }`, `{"greeting":"Hello Mat"}`)
+
+	resp, err := client.Post(httpServer.URL+"/Service.Greet", "application/json", bytes.NewReader([]byte(`{"name":"David"}`)))
+	if err == nil {
+		resp.Body.Close()
+		t.Errorf("expected error for call that was not recorded")
+	}
+}
+
+func expectGreeting(t *testing.T, client *http.Client, url, request, expected string) {
+	t.Helper()
+
+	r, err := http.NewRequest(http.MethodPost, url+"/Service.Greet", bytes.NewReader([]byte(request)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := client.Do(r)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected %d status code, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	if string(body) != expected {
+		t.Errorf("expected %q response body, got %q", expected, string(body))
+	}
+}
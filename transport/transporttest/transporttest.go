@@ -0,0 +1,70 @@
+// Package transporttest provides utilities for testing services served
+// with transport.Server.
+package transporttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/damejeras/gorpc/transport"
+)
+
+// NewRequest returns a POST request calling the method registered on the
+// server with the request encoded as JSON. It fails the test if the method
+// is not registered.
+func NewRequest(tb testing.TB, server transport.Server, service, method string, request interface{}) *http.Request {
+	tb.Helper()
+
+	path, ok := routePath(server, service, method)
+	if !ok {
+		tb.Fatalf("%s.%s is not registered", service, method)
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		tb.Fatalf("%s.%s: marshal request: %v", service, method, err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+
+	return r
+}
+
+// Record calls the method registered on the server and returns the
+// recorded response.
+func Record(tb testing.TB, server transport.Server, service, method string, request interface{}) *httptest.ResponseRecorder {
+	tb.Helper()
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, NewRequest(tb, server, service, method, request))
+
+	return w
+}
+
+// Decode decodes the recorded response into v. It fails the test if
+// the response has not 200 status code or can not be decoded.
+func Decode(tb testing.TB, w *httptest.ResponseRecorder, v interface{}) {
+	tb.Helper()
+
+	if w.Code != http.StatusOK {
+		tb.Fatalf("expected %d status code, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		tb.Fatalf("decode response %q: %v", w.Body.String(), err)
+	}
+}
+
+func routePath(server transport.Server, service, method string) (string, bool) {
+	for _, route := range server.Routes() {
+		if route.Service == service && route.Method == method {
+			return route.Path, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,22 @@
+package transporttest
+
+import (
+	"testing"
+
+	"github.com/damejeras/gorpc/transport"
+)
+
+func TestRecord(t *testing.T) {
+	srv := transport.NewServer(transport.WithPathPrefix("/rpc/"))
+	registerGreet(srv)
+
+	w := Record(t, srv, "Service", "Greet", map[string]string{"name": "Mat"})
+
+	var response struct {
+		Greeting string `json:"greeting"`
+	}
+	Decode(t, w, &response)
+	if response.Greeting != "Hello Mat" {
+		t.Errorf("expected %q greeting, got %q", "Hello Mat", response.Greeting)
+	}
+}
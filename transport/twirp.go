@@ -0,0 +1,149 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// TwirpPrefix is the path prefix of Twirp compatible routes.
+const TwirpPrefix = "/twirp/"
+
+type twirpContextKey struct{}
+
+// twirp maps Twirp routes /twirp/<package>.<Service>/<Method> onto
+// registered methods and formats errors as Twirp errors.
+type twirp struct {
+	packageName string
+}
+
+// parse returns service and method of Twirp route path.
+func (t *twirp) parse(path string) (string, string, bool) {
+	if !strings.HasPrefix(path, TwirpPrefix) {
+		return "", "", false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(path, TwirpPrefix), "/")
+	if len(parts) != 2 {
+		return "", "", false
+	}
+
+	service := parts[0]
+	if t.packageName != "" {
+		if !strings.HasPrefix(service, t.packageName+".") {
+			return "", "", false
+		}
+
+		service = strings.TrimPrefix(service, t.packageName+".")
+	}
+
+	return service, parts[1], service != "" && parts[1] != ""
+}
+
+func isTwirp(ctx context.Context) bool {
+	_, ok := ctx.Value(twirpContextKey{}).(bool)
+
+	return ok
+}
+
+type twirpError struct {
+	Code string            `json:"code"`
+	Msg  string            `json:"msg"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+func (e twirpError) Error() string { return e.Msg }
+
+func twirpBadRoute(r *http.Request) twirpError {
+	return twirpError{Code: "bad_route", Msg: "no handler for path " + strconv.Quote(r.URL.Path)}
+}
+
+// twirpCodes maps Twirp error codes to their HTTP status codes.
+var twirpCodes = map[string]int{
+	"canceled":            http.StatusRequestTimeout,
+	"invalid_argument":    http.StatusBadRequest,
+	"malformed":           http.StatusBadRequest,
+	"deadline_exceeded":   http.StatusRequestTimeout,
+	"not_found":           http.StatusNotFound,
+	"bad_route":           http.StatusNotFound,
+	"already_exists":      http.StatusConflict,
+	"permission_denied":   http.StatusForbidden,
+	"unauthenticated":     http.StatusUnauthorized,
+	"resource_exhausted":  http.StatusTooManyRequests,
+	"failed_precondition": http.StatusPreconditionFailed,
+	"unimplemented":       http.StatusNotImplemented,
+	"internal":            http.StatusInternalServerError,
+	"unavailable":         http.StatusServiceUnavailable,
+}
+
+// twirpCode returns Twirp error code corresponding to HTTP status code.
+func twirpCode(status int) string {
+	switch status {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return "invalid_argument"
+	case http.StatusUnsupportedMediaType:
+		return "malformed"
+	case http.StatusUnauthorized:
+		return "unauthenticated"
+	case http.StatusForbidden:
+		return "permission_denied"
+	case http.StatusNotFound:
+		return "not_found"
+	case StatusClientClosedRequest:
+		return "canceled"
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return "deadline_exceeded"
+	case http.StatusConflict:
+		return "already_exists"
+	case http.StatusPreconditionFailed:
+		return "failed_precondition"
+	case http.StatusTooManyRequests:
+		return "resource_exhausted"
+	case http.StatusNotImplemented:
+		return "unimplemented"
+	case http.StatusServiceUnavailable:
+		return "unavailable"
+	}
+
+	if status >= http.StatusInternalServerError {
+		return "internal"
+	}
+
+	return "invalid_argument"
+}
+
+// writeTwirpError writes the error in Twirp format.
+func writeTwirpError(w http.ResponseWriter, r *http.Request, reg *ErrorRegistry, err error) {
+	twirpErr := toTwirpError(reg, err)
+	if id := RequestIDFromContext(r.Context()); id != "" {
+		meta := map[string]string{"request_id": id}
+		for key, value := range twirpErr.Meta {
+			meta[key] = value
+		}
+
+		twirpErr.Meta = meta
+	}
+
+	_ = Encode(w, r, twirpCodes[twirpErr.Code], twirpErr)
+}
+
+// toTwirpError maps status code of the client error to Twirp error code
+// and keeps it in "http_status" meta. Other errors become internal errors.
+func toTwirpError(reg *ErrorRegistry, err error) twirpError {
+	var twirpErr twirpError
+	if errors.As(err, &twirpErr) {
+		return twirpErr
+	}
+
+	if clientErr, ok := reg.ClientError(err); ok {
+		return twirpError{
+			Code: twirpCode(clientErr.Code),
+			Msg:  clientErr.Message,
+			Meta: map[string]string{"http_status": strconv.Itoa(clientErr.Code)},
+		}
+	}
+
+	return twirpError{Code: "internal", Msg: "internal server error"}
+}
@@ -0,0 +1,92 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithTwirp(t *testing.T) {
+	srv := NewServer(WithTwirp("example.v1"))
+	srv.Register("Service", "Greet", greetHandler(srv))
+
+	tests := []struct {
+		path     string
+		body     string
+		status   int
+		expected string
+	}{
+		{
+			path:     "/twirp/example.v1.Service/Greet",
+			body:     `{"name":"Mat"}`,
+			status:   http.StatusOK,
+			expected: `{"greeting":"Hello Mat"}`,
+		},
+		{
+			path:     "/twirp/example.v1.Service/Greet",
+			body:     `{}`,
+			status:   http.StatusBadRequest,
+			expected: `{"code":"invalid_argument","msg":"name is required","meta":{"http_status":"400"}}`,
+		},
+		{
+			path:     "/twirp/example.v1.Service/Unknown",
+			body:     `{}`,
+			status:   http.StatusNotFound,
+			expected: `{"code":"bad_route","msg":"no handler for path \"/twirp/example.v1.Service/Unknown\""}`,
+		},
+		{
+			path:     "/Service.Greet",
+			body:     `{}`,
+			status:   http.StatusBadRequest,
+			expected: `{"error":"name is required"}`,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+		r.Header.Set("Content-Type", "application/json")
+		srv.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s %s: expected %d status code, got %d", test.path, test.body, test.status, w.Code)
+		}
+
+		if w.Body.String() != test.expected {
+			t.Errorf("%s %s: expected %q response body, got %q", test.path, test.body, test.expected, w.Body.String())
+		}
+	}
+}
+
+func TestTwirpCORS(t *testing.T) {
+	srv := NewServer(WithTwirp("example.v1"), WithCORS(CORSOptions{AllowedOrigins: []string{"https://example.com"}}))
+	srv.Register("Service", "Greet", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"greeting":"Hello"}`))
+	}, WithMetadata(map[string]interface{}{"idempotent": true}))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodOptions, "/twirp/example.v1.Service/Greet", nil)
+	r.Header.Set("Origin", "https://example.com")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected %d status code, got %d", http.StatusNoContent, w.Code)
+	}
+
+	headers := map[string]string{
+		"Allow":                        "POST, OPTIONS",
+		"Access-Control-Allow-Origin":  "https://example.com",
+		"Access-Control-Allow-Methods": "POST, OPTIONS",
+	}
+	for header, expected := range headers {
+		if w.Header().Get(header) != expected {
+			t.Errorf("expected %s header to be %q, got %q", header, expected, w.Header().Get(header))
+		}
+	}
+
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/twirp/example.v1.Service/Greet", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected %d status code for GET, got %d", http.StatusNotFound, w.Code)
+	}
+}